@@ -0,0 +1,22 @@
+package klogger
+
+import (
+	"runtime"
+
+	"github.com/jon-kamis/klogger/internal/utils"
+)
+
+// Function caller returns the function name, file and line of a calling function
+// skip - the number of functions to skip above the function calling caller. 0 returns the function which called it
+func caller(skip int) (string, string, int) {
+	pcs := make([]uintptr, 1)
+
+	//Skip runtime.Callers, this function and the function calling it
+	if runtime.Callers(skip+3, pcs) == 0 {
+		return "", "", 0
+	}
+
+	f, _ := runtime.CallersFrames(pcs).Next()
+
+	return utils.ShortFuncName(f.Function), f.File, f.Line
+}
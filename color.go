@@ -0,0 +1,91 @@
+package klogger
+
+import (
+	"os"
+	"sync"
+
+	"github.com/jon-kamis/klogger/internal/config"
+	"github.com/jon-kamis/klogger/internal/constants"
+	"github.com/jon-kamis/klogger/pkg/loglevel"
+)
+
+// ANSI escape codes used to color console output
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiCyan   = "\x1b[36m"
+	ansiGray   = "\x1b[90m"
+)
+
+// Function levelColor returns the ANSI color used for the level column of a log
+func levelColor(l loglevel.LogLevel) string {
+	switch {
+	case l >= loglevel.Error:
+		return ansiRed
+	case l >= loglevel.Warn:
+		return ansiYellow
+	case l >= loglevel.Info:
+		return ansiGreen
+	case l >= loglevel.Debug:
+		return ansiCyan
+	}
+	return ansiGray
+}
+
+// Function colorize wraps s in the given ANSI color. Empty strings are left as they are
+func colorize(color string, s string) string {
+	if s == "" {
+		return s
+	}
+
+	return color + s + ansiReset
+}
+
+// Function consoleFormatter returns the Formatter for a console output. Text logs are colored according to the ConsoleColor property
+// conf - the config to read the output format and color mode from before each log
+// f - the file written to, checked once to see if it is a terminal
+func consoleFormatter(conf func() config.KloggerConfig, f *os.File) Formatter {
+	tty := sync.OnceValue(func() bool { return isTerminal(f) })
+
+	return func(r Record) string {
+		c := conf()
+
+		if c.LogFormat != constants.FormatJSON && useColor(c.ConsoleColor, tty) {
+			return formatText(r, c.Template, c.Time, true)
+		}
+
+		return formatterFor(c.LogFormat, c)(r)
+	}
+}
+
+// Function useColor returns true if console output should be colored for the given ConsoleColor mode
+// mode - one of the constants.ConsoleColor values. Unknown values are treated as auto
+// tty - returns true if the console is a terminal
+func useColor(mode string, tty func() bool) bool {
+	switch mode {
+	case constants.ConsoleColorAlways:
+		return true
+	case constants.ConsoleColorNever:
+		return false
+	}
+
+	if os.Getenv(constants.NoColorEnvName) != "" {
+		return false
+	}
+
+	return tty()
+}
+
+// Function isTerminal returns true if f is a character device such as a terminal
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+
+	if err != nil {
+		return false
+	}
+
+	return fi.Mode()&os.ModeCharDevice != 0
+}
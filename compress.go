@@ -0,0 +1,18 @@
+package klogger
+
+import (
+	"io"
+
+	"github.com/jon-kamis/klogger/internal/filelogger"
+)
+
+// Type Codec compresses rolled over log files. Codecs are selected by name with the Compression property
+type Codec interface {
+	Extension() string                             //The extension added to compressed file names, such as .zst
+	NewWriter(w io.Writer) (io.WriteCloser, error) //Returns a writer compressing to w. Closing it must flush all compressed data
+}
+
+// Function RegisterCodec adds a Codec which can be selected with the Compression property, such as a zstd Codec. gzip is built in. Names are case-insensitive
+func RegisterCodec(name string, c Codec) {
+	filelogger.RegisterCodec(name, c)
+}
@@ -0,0 +1,72 @@
+package klogger
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jon-kamis/klogger/internal/utils"
+)
+
+// Function SetErrorHandler sets the function receiving errors from writing logs, such as a full disk or a log file which cannot be opened, rolled over or compressed. If nil errors are printed to stderr
+// The handler is called on the goroutine which hit the error. Logs it writes through the same Logger skip the async queue, and errors they cause are printed to stderr instead of being passed to the handler again
+func (lg *Logger) SetErrorHandler(h func(error)) {
+	if h == nil {
+		lg.out.onError.Store(nil)
+		return
+	}
+
+	lg.out.onError.Store(&h)
+}
+
+// Function ErrorCount returns the number of errors reported by the Logger and the copies of it made by With since it was created
+func (lg *Logger) ErrorCount() uint64 {
+	return lg.out.errCount.Load()
+}
+
+// Function reportError counts an error and passes it to the error handler, or prints it to stderr if there is none. nil errors are ignored
+func (lg *Logger) reportError(err error) {
+	if err == nil {
+		return
+	}
+
+	lg.out.errCount.Add(1)
+
+	if h := lg.out.onError.Load(); h != nil && !lg.inErrorHandler() {
+		id := utils.GoroutineID()
+
+		lg.out.handling.Store(id, struct{}{})
+		lg.out.handlers.Add(1)
+
+		defer func() {
+			lg.out.handlers.Add(-1)
+			lg.out.handling.Delete(id)
+		}()
+
+		(*h)(err)
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "[Klogger] %v\n", err)
+}
+
+// Function inErrorHandler returns true if the calling goroutine is running the error handler
+func (lg *Logger) inErrorHandler() bool {
+	//Avoid reading the goroutine id unless a handler is running
+	if lg.out.handlers.Load() == 0 {
+		return false
+	}
+
+	_, ok := lg.out.handling.Load(utils.GoroutineID())
+
+	return ok
+}
+
+// Function SetErrorHandler sets the function receiving errors from writing logs with the default Logger. If nil errors are printed to stderr
+func SetErrorHandler(h func(error)) {
+	std.SetErrorHandler(h)
+}
+
+// Function ErrorCount returns the number of errors reported by the default Logger
+func ErrorCount() uint64 {
+	return std.ErrorCount()
+}
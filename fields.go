@@ -0,0 +1,85 @@
+package klogger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Type Field is a key/value pair attached to a log so that it can be searched on
+type Field struct {
+	Key   string
+	Value any
+}
+
+const badKey = "!BADKEY"
+
+// Function With returns a copy of the Logger which attaches the given key/value pairs to every log it writes
+// kv - alternating keys and values. Keys should be strings
+func (lg *Logger) With(kv ...any) *Logger {
+	nl := *lg
+	nl.fields = appendFields(lg.fields, kv)
+
+	return &nl
+}
+
+// Function appendFields converts alternating key/value pairs into Fields and appends them to a copy of fs
+func appendFields(fs []Field, kv []any) []Field {
+	if len(kv) == 0 {
+		return fs
+	}
+
+	nfs := make([]Field, len(fs), len(fs)+(len(kv)+1)/2)
+	copy(nfs, fs)
+
+	for i := 0; i < len(kv); i++ {
+
+		//Field values may be passed directly
+		if f, ok := kv[i].(Field); ok {
+			nfs = append(nfs, f)
+			continue
+		}
+
+		k, ok := kv[i].(string)
+
+		//Keys which are not strings or have no value are kept under a placeholder key so they are not lost
+		if !ok || i+1 >= len(kv) {
+			nfs = append(nfs, Field{Key: badKey, Value: kv[i]})
+			continue
+		}
+
+		nfs = append(nfs, Field{Key: k, Value: kv[i+1]})
+		i++
+	}
+
+	return nfs
+}
+
+// Function formatFields renders fields as space separated key=value pairs with a leading space
+func formatFields(fs []Field) string {
+	if len(fs) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+
+	for _, f := range fs {
+		sb.WriteString(" ")
+		sb.WriteString(f.Key)
+		sb.WriteString("=")
+		sb.WriteString(formatValue(f.Value))
+	}
+
+	return sb.String()
+}
+
+// Function formatValue renders a single field value, quoting it if it would be ambiguous
+func formatValue(v any) string {
+	s := fmt.Sprint(v)
+
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return strconv.Quote(s)
+	}
+
+	return s
+}
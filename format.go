@@ -0,0 +1,209 @@
+package klogger
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jon-kamis/klogger/internal/config"
+	"github.com/jon-kamis/klogger/internal/constants"
+	"github.com/jon-kamis/klogger/internal/linetemplate"
+	"github.com/jon-kamis/klogger/internal/timefmt"
+	"github.com/jon-kamis/klogger/pkg/loglevel"
+)
+
+// Type Record holds a single log before it is formatted and written by a Sink
+type Record struct {
+	Time      time.Time
+	Level     loglevel.LogLevel
+	Method    string
+	File      string //The caller's file, only set when the method is detected automatically or the LogTemplate uses it
+	Line      int    //The caller's line, only set when the method is detected automatically or the LogTemplate uses it
+	Goroutine uint64 //The id of the goroutine which wrote the log, only set when the LogTemplate uses it
+	Message   string
+	Fields    []Field
+}
+
+// Type Formatter formats a Record into the text written by a Sink, without a trailing new line
+type Formatter func(r Record) string
+
+// var defaultTemplate is the line template used by FormatText
+var defaultTemplate, _ = linetemplate.Parse(constants.DefaultLogTemplateValue)
+
+// var hostname is the host name written by the {host} token
+var hostname = sync.OnceValue(func() string {
+	h, _ := os.Hostname()
+	return h
+})
+
+// Function formatterFor returns the Formatter for an output format name. Unknown formats are written as text
+// f - the output format name
+// c - the config holding the line template and timestamp format
+func formatterFor(f string, c config.KloggerConfig) Formatter {
+	switch f {
+	case constants.FormatJSON:
+		return func(r Record) string { return formatJSON(r, c.Time) }
+	default:
+		return func(r Record) string { return formatText(r, c.Template, c.Time, false) }
+	}
+}
+
+// Function NewFormatter returns the Formatter klogger uses for an output format, either text or json, with the LogTemplate and timestamp settings of o
+func NewFormatter(format string, o Options) Formatter {
+	return formatterFor(strings.ToLower(format), o.toConfig())
+}
+
+// Function FormatText formats a Record using the default line template and timestamp format. Each line of a multi-line message is written on its own line. Use NewFormatter for the configured settings
+func FormatText(r Record) string {
+	return formatText(r, defaultTemplate, timefmt.Format{}, false)
+}
+
+// Function NewTextFormatter returns a Formatter writing text logs using a line template such as "{time} [{level}] {method} - {msg}{fields}"
+// Timestamps are written in the default format. Use NewFormatter with Options holding the template for the configured timestamp settings
+// Returns an error if the template contains unknown tokens. See the README for the available tokens
+func NewTextFormatter(template string) (Formatter, error) {
+	t, err := linetemplate.Parse(template)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return func(r Record) string { return formatText(r, t, timefmt.Format{}, false) }, nil
+}
+
+// Function formatText formats a Record as text using a line template. Each line of a multi-line message is written on its own line
+// tf - the format of the timestamp
+// color - whether the level and method are colored using ANSI escape codes
+func formatText(r Record, t linetemplate.Template, tf timefmt.Format, color bool) string {
+	if len(t) == 0 {
+		t = defaultTemplate
+	}
+
+	msgArr := strings.Split(r.Message, "\n")
+
+	var sb strings.Builder
+
+	for i, m := range msgArr {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+
+		for _, sg := range t {
+			if sg.Token == "" {
+				sb.WriteString(sg.Text)
+				continue
+			}
+
+			writeToken(&sb, r, sg.Token, m, tf, color)
+		}
+	}
+
+	return sb.String()
+}
+
+// Function writeToken writes the value of a single template token for a line of a Record
+// m - the line of the message being written
+func writeToken(sb *strings.Builder, r Record, token string, m string, tf timefmt.Format, color bool) {
+	switch token {
+	case linetemplate.Time:
+		sb.WriteString(tf.Format(r.Time))
+	case linetemplate.Level:
+		if color {
+			sb.WriteString(colorize(levelColor(r.Level), r.Level.String()))
+		} else {
+			sb.WriteString(r.Level.String())
+		}
+	case linetemplate.Method:
+		if color {
+			sb.WriteString(colorize(ansiBold, r.Method))
+		} else {
+			sb.WriteString(r.Method)
+		}
+	case linetemplate.File:
+		if r.File != "" {
+			sb.WriteString(filepath.Base(r.File))
+		}
+	case linetemplate.Line:
+		sb.WriteString(strconv.Itoa(r.Line))
+	case linetemplate.Caller:
+		if r.File != "" {
+			sb.WriteString(filepath.Base(r.File) + ":" + strconv.Itoa(r.Line))
+		}
+	case linetemplate.Goroutine:
+		sb.WriteString(strconv.FormatUint(r.Goroutine, 10))
+	case linetemplate.Host:
+		sb.WriteString(hostname())
+	case linetemplate.PID:
+		sb.WriteString(strconv.Itoa(os.Getpid()))
+	case linetemplate.Msg:
+		sb.WriteString(m)
+	case linetemplate.Fields:
+		//Fields are written on every line so that each one can be searched on
+		sb.WriteString(formatFields(r.Fields))
+	}
+}
+
+// Function FormatJSON formats a Record as a single JSON object with the default timestamp format. Use NewFormatter for the configured settings
+func FormatJSON(r Record) string {
+	return formatJSON(r, timefmt.Format{})
+}
+
+// Function formatJSON formats a Record as a single JSON object. Numeric timestamps are written as JSON numbers
+// tf - the format of the timestamp
+func formatJSON(r Record, tf timefmt.Format) string {
+	var sb strings.Builder
+
+	sb.WriteString(`{"time":`)
+
+	if tf.IsNumeric() {
+		sb.WriteString(tf.Format(r.Time))
+	} else {
+		writeJSONValue(&sb, tf.Format(r.Time))
+	}
+
+	sb.WriteString(`,"level":`)
+	writeJSONValue(&sb, r.Level.String())
+	sb.WriteString(`,"method":`)
+	writeJSONValue(&sb, r.Method)
+	sb.WriteString(`,"message":`)
+	writeJSONValue(&sb, r.Message)
+
+	if len(r.Fields) > 0 {
+		sb.WriteString(`,"fields":{`)
+
+		for i, f := range r.Fields {
+			if i > 0 {
+				sb.WriteString(",")
+			}
+			writeJSONValue(&sb, f.Key)
+			sb.WriteString(":")
+			writeJSONValue(&sb, f.Value)
+		}
+
+		sb.WriteString("}")
+	}
+
+	sb.WriteString("}")
+
+	return sb.String()
+}
+
+// Function writeJSONValue writes v as JSON. Errors are written as their message and values which cannot be marshalled as their string form
+func writeJSONValue(sb *strings.Builder, v any) {
+	if err, ok := v.(error); ok {
+		v = err.Error()
+	}
+
+	b, err := json.Marshal(v)
+
+	if err != nil {
+		b, _ = json.Marshal(fmt.Sprint(v))
+	}
+
+	sb.Write(b)
+}
@@ -0,0 +1,166 @@
+package asyncqueue
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/jon-kamis/klogger/internal/constants"
+)
+
+// Type Queue is a bounded queue drained by a single background goroutine
+type Queue[T any] struct {
+	ch      chan T
+	policy  string       //What to do when the queue is full. One of the constants.AsyncOverflow values
+	handle  func(T)      //Called by the background goroutine for each queued value
+	dropped func(int64)  //Called by the background goroutine with the number of values dropped since it was last called
+	drops   atomic.Int64 //Values dropped which have not yet been reported
+
+	mu     sync.RWMutex //Held for reading while sending so that the channel is not closed mid send
+	closed bool
+	done   chan struct{} //Closed when the background goroutine exits
+
+	cmu     sync.Mutex //Guards queued and handled
+	cond    *sync.Cond
+	queued  int64 //Values accepted by Enqueue
+	handled int64 //Values handled or dropped
+}
+
+// Function New starts a Queue holding up to size values
+// size - the capacity of the queue. Values below 1 are treated as 1
+// policy - the overflow policy to use when the queue is full
+// handle - called for each value in the order they were queued
+// dropped - optional, called after a value is handled if values have been dropped
+func New[T any](size int, policy string, handle func(T), dropped func(int64)) *Queue[T] {
+	if size < 1 {
+		size = 1
+	}
+
+	q := &Queue[T]{
+		ch:      make(chan T, size),
+		policy:  policy,
+		handle:  handle,
+		dropped: dropped,
+		done:    make(chan struct{}),
+	}
+	q.cond = sync.NewCond(&q.cmu)
+
+	go q.run()
+
+	return q
+}
+
+// Function Enqueue adds a value to the queue following the overflow policy. Returns false if the queue is closed and the value was not accepted
+func (q *Queue[T]) Enqueue(v T) bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		return false
+	}
+
+	q.cmu.Lock()
+	q.queued++
+	q.cmu.Unlock()
+
+	switch q.policy {
+	case constants.AsyncOverflowDropNewest:
+		select {
+		case q.ch <- v:
+		default:
+			q.drop()
+		}
+
+	case constants.AsyncOverflowDropOldest:
+		for {
+			select {
+			case q.ch <- v:
+				return true
+			default:
+			}
+
+			//Make room by discarding the value at the head of the queue
+			select {
+			case <-q.ch:
+				q.drop()
+			default:
+			}
+		}
+
+	default:
+		q.ch <- v
+	}
+
+	return true
+}
+
+// Function Flush blocks until every value queued before it was called has been handled or dropped
+func (q *Queue[T]) Flush() {
+	q.cmu.Lock()
+
+	target := q.queued
+
+	for q.handled < target {
+		q.cond.Wait()
+	}
+
+	q.cmu.Unlock()
+
+	//Values dropped at the end of the queue are reported here as there is no later value to report them after
+	q.reportDrops()
+}
+
+// Function Close stops accepting values, handles those already queued and stops the background goroutine
+func (q *Queue[T]) Close() {
+	q.mu.Lock()
+
+	if q.closed {
+		q.mu.Unlock()
+		<-q.done
+		return
+	}
+
+	q.closed = true
+	close(q.ch)
+	q.mu.Unlock()
+
+	<-q.done
+}
+
+// Function run handles queued values until the queue is closed
+func (q *Queue[T]) run() {
+	defer close(q.done)
+
+	for v := range q.ch {
+		q.handle(v)
+		q.reportDrops()
+		q.finish()
+	}
+
+	q.reportDrops()
+}
+
+// Function reportDrops passes the number of values dropped since the last report to the dropped callback
+func (q *Queue[T]) reportDrops() {
+	if q.dropped == nil || q.drops.Load() == 0 {
+		return
+	}
+
+	if n := q.drops.Swap(0); n > 0 {
+		q.dropped(n)
+	}
+}
+
+// Function drop records a value being dropped
+func (q *Queue[T]) drop() {
+	q.drops.Add(1)
+	q.finish()
+}
+
+// Function finish marks a single queued value as finished and wakes any waiting Flush calls
+func (q *Queue[T]) finish() {
+	q.cmu.Lock()
+	q.handled++
+	q.cmu.Unlock()
+
+	q.cond.Broadcast()
+}
@@ -0,0 +1,118 @@
+package asyncqueue
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/jon-kamis/klogger/internal/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+// Function newBlockedQueue returns a queue whose handler waits on release before handling any value. started is closed once the handler has taken the first value
+func newBlockedQueue(size int, policy string) (q *Queue[int], handled *[]int, dropped *int64, started chan struct{}, release chan struct{}) {
+	var mu sync.Mutex
+	var once sync.Once
+	handled = &[]int{}
+	dropped = new(int64)
+	started = make(chan struct{})
+	release = make(chan struct{})
+
+	q = New(size, policy, func(v int) {
+		once.Do(func() { close(started) })
+		<-release
+		mu.Lock()
+		*handled = append(*handled, v)
+		mu.Unlock()
+	}, func(n int64) {
+		*dropped += n
+	})
+
+	return q, handled, dropped, started, release
+}
+
+func TestEnqueueBlock(t *testing.T) {
+	var handled []int
+
+	q := New(2, constants.AsyncOverflowBlock, func(v int) { handled = append(handled, v) }, nil)
+
+	for i := 0; i < 100; i++ {
+		assert.True(t, q.Enqueue(i))
+	}
+
+	q.Flush()
+
+	//Every value should be handled in order
+	assert.Equal(t, 100, len(handled))
+	for i, v := range handled {
+		assert.Equal(t, i, v)
+	}
+
+	q.Close()
+}
+
+func TestEnqueueDropNewest(t *testing.T) {
+	q, handled, dropped, started, release := newBlockedQueue(2, constants.AsyncOverflowDropNewest)
+
+	//The first value is taken by the handler, the next two fill the queue and the rest are dropped
+	q.Enqueue(0)
+	<-started
+	for i := 1; i < 6; i++ {
+		q.Enqueue(i)
+	}
+
+	close(release)
+	q.Close()
+
+	assert.Equal(t, []int{0, 1, 2}, *handled)
+	assert.Equal(t, int64(3), *dropped)
+}
+
+func TestEnqueueDropOldest(t *testing.T) {
+	q, handled, dropped, started, release := newBlockedQueue(2, constants.AsyncOverflowDropOldest)
+
+	q.Enqueue(0)
+	<-started
+	for i := 1; i < 6; i++ {
+		q.Enqueue(i)
+	}
+
+	close(release)
+	q.Close()
+
+	//The newest values should be kept
+	assert.Equal(t, []int{0, 4, 5}, *handled)
+	assert.Equal(t, int64(3), *dropped)
+}
+
+func TestFlushReportsDrops(t *testing.T) {
+	q, _, dropped, started, release := newBlockedQueue(1, constants.AsyncOverflowDropNewest)
+
+	q.Enqueue(0)
+	<-started
+	q.Enqueue(1)
+	q.Enqueue(2)
+
+	close(release)
+	q.Flush()
+
+	assert.Equal(t, int64(1), *dropped)
+	q.Close()
+}
+
+func TestClose(t *testing.T) {
+	var handled []int
+
+	q := New(10, constants.AsyncOverflowBlock, func(v int) { handled = append(handled, v) }, nil)
+
+	q.Enqueue(1)
+	q.Enqueue(2)
+	q.Close()
+
+	//Values queued before Close are still handled
+	assert.Equal(t, []int{1, 2}, handled)
+
+	//Values are refused after Close and Close may be called again
+	assert.False(t, q.Enqueue(3))
+	q.Close()
+	q.Flush()
+}
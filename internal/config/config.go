@@ -1,28 +1,64 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"strings"
 	"sync/atomic"
 
 	"github.com/jon-kamis/klogger/internal/constants"
+	"github.com/jon-kamis/klogger/internal/linetemplate"
 	"github.com/jon-kamis/klogger/internal/properties"
+	"github.com/jon-kamis/klogger/internal/rollname"
+	"github.com/jon-kamis/klogger/internal/rollover"
+	"github.com/jon-kamis/klogger/internal/timefmt"
 	"github.com/jon-kamis/klogger/pkg/loglevel"
 )
 
 // Type KloggerConfig is a struct holding properties for the application
 type KloggerConfig struct {
-	PropFileName    string
-	LogFileName     string
-	LogFileDir      string
-	DoRollover      bool
-	DoSizeRollover  bool
-	DoDateRollover  bool
-	RolloverSize    int64
-	LogLevel        loglevel.LogLevel
-	LogFileLevel    loglevel.LogLevel
-	EnterLogLevel   loglevel.LogLevel
-	ExitLogLevel    loglevel.LogLevel
-	DoEnterExitLogs bool
+	PropFileName        string
+	LogFileName         string
+	LogFileDir          string
+	DoRollover          bool
+	DoSizeRollover      bool
+	DoDateRollover      bool
+	RolloverSize        int64
+	LogLevel            loglevel.LogLevel
+	LogFileLevel        loglevel.LogLevel
+	EnterLogLevel       loglevel.LogLevel
+	ExitLogLevel        loglevel.LogLevel
+	DoEnterExitLogs     bool
+	LogFormat           string
+	LogFileFormat       string
+	DoAsync             bool
+	AsyncQueueSize      int
+	AsyncOverflowPolicy string
+	SyncPolicy          string
+	SyncInterval        int
+	SyncLogLevel        loglevel.LogLevel
+	SlowExitThreshold   int
+	SlowExitLogLevel    loglevel.LogLevel
+	CallerSkip          int
+	StderrLevel         loglevel.LogLevel
+	ConsoleColor        string
+	LogTemplate         string
+	Template            linetemplate.Template //LogTemplate parsed when the config is loaded
+	LogTimeFormat       string
+	LogTimeZone         string
+	LogTimePrecision    int
+	Time                timefmt.Format //LogTimeFormat, LogTimeZone and LogTimePrecision parsed when the config is loaded
+	RolloverInterval    string
+	Rollover            rollover.Interval //RolloverInterval parsed when the config is loaded
+	MaxBackups          int
+	MaxAgeDays          int
+	MaxTotalSize        int64
+	Compression         string
+	RolloverNamePattern string
+	RolloverName        rollname.Pattern //RolloverNamePattern parsed when the config is loaded
+	RotateOnStartup     bool
+	FileCheckInterval   int
+	FileErrorPolicy     string
 }
 
 var c atomic.Pointer[KloggerConfig] //Pointer cache
@@ -51,25 +87,112 @@ func RefreshConfig() KloggerConfig {
 	return *cached
 }
 
+// Function Default returns a config built only from the default property values
+func Default() KloggerConfig {
+	return fromProperties(properties.DefaultProperties)
+}
+
 func loadConfig() KloggerConfig {
+	//Load in properties
+	return fromProperties(properties.GetProperties())
+}
+
+// Function ParseTemplate parses a LogTemplate. An empty template uses the default template. If the template is invalid an error is printed and the default template is returned
+func ParseTemplate(s string) linetemplate.Template {
+	if s == "" {
+		s = constants.DefaultLogTemplateValue
+	}
+
+	t, err := linetemplate.Parse(s)
+
+	if err != nil {
+		fmt.Printf("[Klogger] invalid %s %q, using the default: %v\n", constants.LogTemplate, s, err)
+		t, _ = linetemplate.Parse(constants.DefaultLogTemplateValue)
+	}
+
+	return t
+}
+
+// Function ParseTimeFormat parses the timestamp settings. If they are invalid an error is printed and the default timestamp format is returned
+func ParseTimeFormat(layout string, zone string, precision int) timefmt.Format {
+	f, err := timefmt.New(layout, zone, precision)
+
+	if err != nil {
+		fmt.Printf("[Klogger] invalid timestamp settings, using the default: %v\n", err)
+	}
+
+	return f
+}
+
+// Function ParseRolloverInterval parses a RolloverInterval. If the interval is invalid an error is printed and a daily interval is returned
+func ParseRolloverInterval(s string) rollover.Interval {
+	i, err := rollover.Parse(s)
+
+	if err != nil {
+		fmt.Printf("[Klogger] %v, using the default: %s\n", err, constants.DefaultRolloverIntervalValue)
+	}
+
+	return i
+}
+
+// Function ParseRolloverNamePattern parses a RolloverNamePattern. If the pattern is invalid an error is printed and the default pattern is returned
+func ParseRolloverNamePattern(s string) rollname.Pattern {
+	p, err := rollname.Parse(s)
+
+	if err != nil {
+		fmt.Printf("[Klogger] %v, using the default: %s\n", err, constants.DefaultRolloverNamePatternValue)
+	}
+
+	return p
+}
+
+// Function fromProperties converts loaded properties into a KloggerConfig
+func fromProperties(props properties.KloggerProperties) KloggerConfig {
 	//Read in Config
 	var config KloggerConfig
 
-	//Load in properties
-	props := properties.GetProperties()
-
 	//Read in the rest of the props
 	config.LogFileDir = properties.GetPropString(props.LogFileDir)
 	config.LogFileName = properties.GetPropString(props.LogFileName)
 	config.DoRollover = properties.GetPropBool(props.DoRollover)
 	config.DoSizeRollover = properties.GetPropBool(props.DoSizeRollover)
 	config.DoDateRollover = properties.GetPropBool(props.DoDateRollover)
-	config.RolloverSize = int64(properties.GetPropInt(props.RolloverSize))
+	config.RolloverSize = properties.GetPropInt64(props.RolloverSize)
 	config.LogFileLevel = properties.GetPropLogLevel(props.LogFileLevel)
 	config.LogLevel = properties.GetPropLogLevel(props.LogLevel)
 	config.EnterLogLevel = properties.GetPropLogLevel(props.EnterLogLevel)
 	config.ExitLogLevel = properties.GetPropLogLevel(props.ExitLogLevel)
 	config.DoEnterExitLogs = properties.GetPropBool(props.DoEnterExitLogs)
+	config.LogFormat = strings.ToLower(properties.GetPropString(props.LogFormat))
+	config.LogFileFormat = strings.ToLower(properties.GetPropString(props.LogFileFormat))
+	config.DoAsync = properties.GetPropBool(props.DoAsync)
+	config.AsyncQueueSize = properties.GetPropInt(props.AsyncQueueSize)
+	config.AsyncOverflowPolicy = strings.ToLower(properties.GetPropString(props.AsyncOverflowPolicy))
+	config.SyncPolicy = strings.ToLower(properties.GetPropString(props.SyncPolicy))
+	config.SyncInterval = properties.GetPropInt(props.SyncInterval)
+	config.SyncLogLevel = properties.GetPropLogLevel(props.SyncLogLevel)
+	config.SlowExitThreshold = properties.GetPropInt(props.SlowExitThreshold)
+	config.SlowExitLogLevel = properties.GetPropLogLevel(props.SlowExitLogLevel)
+	config.CallerSkip = properties.GetPropInt(props.CallerSkip)
+	config.StderrLevel = properties.GetPropLogLevel(props.StderrLevel)
+	config.ConsoleColor = strings.ToLower(properties.GetPropString(props.ConsoleColor))
+	config.LogTemplate = properties.GetPropString(props.LogTemplate)
+	config.Template = ParseTemplate(config.LogTemplate)
+	config.LogTimeFormat = properties.GetPropString(props.LogTimeFormat)
+	config.LogTimeZone = properties.GetPropString(props.LogTimeZone)
+	config.LogTimePrecision = properties.GetPropInt(props.LogTimePrecision)
+	config.Time = ParseTimeFormat(config.LogTimeFormat, config.LogTimeZone, config.LogTimePrecision)
+	config.RolloverInterval = strings.ToLower(properties.GetPropString(props.RolloverInterval))
+	config.Rollover = ParseRolloverInterval(config.RolloverInterval)
+	config.MaxBackups = properties.GetPropInt(props.MaxBackups)
+	config.MaxAgeDays = properties.GetPropInt(props.MaxAgeDays)
+	config.MaxTotalSize = properties.GetPropInt64(props.MaxTotalSize)
+	config.Compression = strings.ToLower(properties.GetPropString(props.Compression))
+	config.RolloverNamePattern = properties.GetPropString(props.RolloverNamePattern)
+	config.RolloverName = ParseRolloverNamePattern(config.RolloverNamePattern)
+	config.RotateOnStartup = properties.GetPropBool(props.RotateOnStartup)
+	config.FileCheckInterval = properties.GetPropInt(props.FileCheckInterval)
+	config.FileErrorPolicy = strings.ToLower(properties.GetPropString(props.FileErrorPolicy))
 
 	return config
 }
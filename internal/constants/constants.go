@@ -14,6 +14,32 @@ const LogFileLevel = "LogFileLevel"
 const EnterLogLevel = "EnterLogLevel"
 const ExitLogLevel = "ExitLogLevel"
 const DoEnterExitLogs = "DoEnterExitLogs"
+const LogFormat = "LogFormat"
+const LogFileFormat = "LogFileFormat"
+const DoAsync = "DoAsync"
+const AsyncQueueSize = "AsyncQueueSize"
+const AsyncOverflowPolicy = "AsyncOverflowPolicy"
+const SyncPolicy = "SyncPolicy"
+const SyncInterval = "SyncInterval"
+const SyncLogLevel = "SyncLogLevel"
+const SlowExitThreshold = "SlowExitThreshold"
+const SlowExitLogLevel = "SlowExitLogLevel"
+const CallerSkip = "CallerSkip"
+const StderrLevel = "StderrLevel"
+const ConsoleColor = "ConsoleColor"
+const LogTemplate = "LogTemplate"
+const LogTimeFormat = "LogTimeFormat"
+const LogTimeZone = "LogTimeZone"
+const LogTimePrecision = "LogTimePrecision"
+const RolloverInterval = "RolloverInterval"
+const MaxBackups = "MaxBackups"
+const MaxAgeDays = "MaxAgeDays"
+const MaxTotalSize = "MaxTotalSize"
+const Compression = "Compression"
+const RolloverNamePattern = "RolloverNamePattern"
+const RotateOnStartup = "RotateOnStartup"
+const FileCheckInterval = "FileCheckInterval"
+const FileErrorPolicy = "FileErrorPolicy"
 
 const EnvPrefix = "Klogger"
 
@@ -28,14 +54,81 @@ const DefaultEnterLogLevelValue = loglevel.Info
 const DefaultExitLogLevelValue = loglevel.Info
 const DefaultDoEnterExitLogs = true
 const DefaultDoDateRolloverValue = true
+const DefaultLogFormatValue = FormatText
+const DefaultLogFileFormatValue = FormatText
+const DefaultDoAsyncValue = false
+const DefaultAsyncQueueSizeValue = 1024
+const DefaultAsyncOverflowPolicyValue = AsyncOverflowBlock
+const DefaultSyncPolicyValue = SyncPolicyAlways
+const DefaultSyncIntervalValue = 1000
+const DefaultSyncLogLevelValue = loglevel.Error
+const DefaultSlowExitThresholdValue = 0
+const DefaultSlowExitLogLevelValue = loglevel.Warn
+const DefaultCallerSkipValue = 0
+const DefaultStderrLevelValue = loglevel.None
+const DefaultConsoleColorValue = ConsoleColorAuto
+const DefaultLogTemplateValue = "{time} {level} {method} {msg}{fields}"
+const DefaultLogTimeFormatValue = TimeFormat
+const DefaultLogTimeZoneValue = "Local"
+const DefaultLogTimePrecisionValue = 0
+const DefaultRolloverIntervalValue = "day"
+const DefaultMaxBackupsValue = 0
+const DefaultMaxAgeDaysValue = 0
+const DefaultMaxTotalSizeValue = 0
+const DefaultCompressionValue = CompressionNone
+const DefaultRolloverNamePatternValue = "{base}_{date}_{n}{ext}"
+const DefaultRotateOnStartupValue = false
+const DefaultFileCheckIntervalValue = 1000
+const DefaultFileErrorPolicyValue = FileErrorPolicyStderr
 
 const TimeFormat = "2006-01-02 15:04:05"
 
 // Default Byte Size to rollover file
 const DefaultRolloverSize = 104857600
 
+// Method written with logs produced by klogger itself
+const KloggerMethod = "[Klogger]"
+
 const Enter = "[ENTER]"
 const Exit = "[EXIT]"
-const StdMsg = "%v %s %s %s"
+
+// Field key used for the duration written by ExitSince
+const ElapsedField = "elapsed"
+
+// Output formats for stdout and log files
+const FormatText = "text"
+const FormatJSON = "json"
+
+// Policies for when the async queue is full. Values are compared in lower case
+const AsyncOverflowBlock = "block"
+const AsyncOverflowDropNewest = "dropnewest"
+const AsyncOverflowDropOldest = "dropoldest"
+
+// Policies for when the log file is synced to disk. Values are compared in lower case
+const SyncPolicyAlways = "always"
+const SyncPolicyInterval = "interval"
+const SyncPolicyNever = "never"
+const SyncPolicyOnLevel = "onlevel"
+
+// Policies for logs which cannot be written to the log file. Values are compared in lower case
+const FileErrorPolicyStderr = "stderr"
+const FileErrorPolicyRetry = "retry"
+const FileErrorPolicyDrop = "drop"
+
+// Number of times the retry policy tries to write a log again and the milliseconds it waits before the first try. The wait doubles after each try
+const FileErrorRetries = 3
+const FileErrorRetryBackoff = 10
+
+// Built in codecs for compressing rolled over log files. Values are compared in lower case
+const CompressionNone = "none"
+const CompressionGzip = "gzip"
+
+// Modes for coloring console output. Values are compared in lower case
+const ConsoleColorAuto = "auto"
+const ConsoleColorAlways = "always"
+const ConsoleColorNever = "never"
+
+// Environment variable which disables automatic console colors when set to a non-empty value. See https://no-color.org
+const NoColorEnvName = "NO_COLOR"
 
 const UseCacheEnvName = "UseCache"
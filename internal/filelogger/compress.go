@@ -0,0 +1,195 @@
+package filelogger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jon-kamis/klogger/internal/config"
+	"github.com/jon-kamis/klogger/internal/constants"
+)
+
+// Type Codec compresses rolled over log files
+type Codec interface {
+	Extension() string                             //The extension added to compressed file names, such as .gz
+	NewWriter(w io.Writer) (io.WriteCloser, error) //Returns a writer compressing to w. Closing it must flush all compressed data
+}
+
+// Type gzipCodec is the built in gzip Codec
+type gzipCodec struct{}
+
+// Function Extension returns the gzip file extension
+func (gzipCodec) Extension() string {
+	return ".gz"
+}
+
+// Function NewWriter returns a gzip writer
+func (gzipCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriter(w), nil
+}
+
+var codecsMu sync.RWMutex
+var codecs = map[string]Codec{
+	constants.CompressionGzip: gzipCodec{},
+}
+
+// Function RegisterCodec registers a Codec which can be selected by name with the Compression property. Names are case-insensitive and registering an existing name replaces it
+func RegisterCodec(name string, c Codec) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+
+	codecs[strings.ToLower(name)] = c
+}
+
+// Function codecFor returns the Codec registered for a name
+func codecFor(name string) (Codec, bool) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+
+	c, ok := codecs[name]
+
+	return c, ok
+}
+
+// Function codecExtensions returns the file extensions of every registered Codec, longest first
+func codecExtensions() []string {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+
+	var exts []string
+
+	for _, c := range codecs {
+		exts = append(exts, c.Extension())
+	}
+
+	sort.Slice(exts, func(i, j int) bool { return len(exts[i]) > len(exts[j]) })
+
+	return exts
+}
+
+// Function compressRolledFile compresses a rolled over log file in the background using the Codec chosen by the Compression property, then applies the retention limits. The caller must hold fl.mu
+// Returns false if compression is disabled, in which case nothing is started
+func (fl *FileLogger) compressRolledFile(c config.KloggerConfig, fn string) bool {
+	if c.Compression == "" || c.Compression == constants.CompressionNone {
+		return false
+	}
+
+	codec, ok := codecFor(c.Compression)
+
+	if !ok {
+		fl.deferError(fmt.Errorf("unknown compression codec %q, leaving %s uncompressed", c.Compression, fn))
+		return false
+	}
+
+	//Both the file and its compressed copy briefly exist, and neither may be pruned until compression finishes
+	paths := []string{fn, fn + codec.Extension()}
+	fl.setInProgress(paths, true)
+
+	fl.compressing.Add(1)
+
+	go func() {
+		defer fl.compressing.Done()
+
+		if err := compressFile(codec, fn); err != nil {
+			fl.reportError(fmt.Errorf("failed to compress %s: %w", fn, err))
+		}
+
+		fl.setInProgress(paths, false)
+		fl.pruneRolledFiles(c, fl.reportError)
+	}()
+
+	return true
+}
+
+// Function setInProgress marks paths as being compressed, or no longer being compressed, so that pruning skips them
+func (fl *FileLogger) setInProgress(paths []string, b bool) {
+	fl.pruneMu.Lock()
+	defer fl.pruneMu.Unlock()
+
+	if fl.inProgress == nil {
+		fl.inProgress = map[string]bool{}
+	}
+
+	for _, p := range paths {
+		if b {
+			fl.inProgress[p] = true
+		} else {
+			delete(fl.inProgress, p)
+		}
+	}
+}
+
+// Function compressFile writes a compressed copy of fn next to it and removes fn once the copy is complete
+func compressFile(codec Codec, fn string) error {
+	in, err := os.Open(fn)
+
+	if err != nil {
+		return err
+	}
+
+	defer in.Close()
+
+	//Compress to a temporary name so that a partly written file is never mistaken for a rolled over file
+	out := fn + codec.Extension()
+	tmp := out + ".tmp"
+
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+
+	if err != nil {
+		return err
+	}
+
+	err = copyCompressed(codec, f, in)
+
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	//Keep the modification time of the log file so that retention sorts compressed files by when they were written
+	if fi, err := in.Stat(); err == nil {
+		os.Chtimes(tmp, fi.ModTime(), fi.ModTime())
+	}
+
+	if err := os.Rename(tmp, out); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	in.Close()
+
+	return os.Remove(fn)
+}
+
+// Function copyCompressed compresses everything read from r into f
+func copyCompressed(codec Codec, f *os.File, r io.Reader) error {
+	w, err := codec.NewWriter(f)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(w, r); err != nil {
+		w.Close()
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return f.Sync()
+}
+
+// Function WaitCompression blocks until every rolled over file being compressed in the background has been compressed
+func (fl *FileLogger) WaitCompression() {
+	fl.compressing.Wait()
+}
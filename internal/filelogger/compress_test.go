@@ -0,0 +1,135 @@
+package filelogger
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jon-kamis/klogger/internal/config"
+	"github.com/jon-kamis/klogger/internal/constants"
+	"github.com/jon-kamis/klogger/pkg/loglevel"
+	"github.com/stretchr/testify/assert"
+)
+
+// Type nopCodec is a Codec which writes files unchanged under a different extension
+type nopCodec struct{}
+
+func (nopCodec) Extension() string { return ".nop" }
+
+func (nopCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return nopWriteCloser{w}, nil
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+func TestCompression(t *testing.T) {
+	dir := t.TempDir()
+	c := testConfig(dir)
+	c.DoDateRollover = false
+	c.RolloverSize = 10
+	c.Compression = constants.CompressionGzip
+
+	fl := New(func() config.KloggerConfig { return c })
+	defer fl.CloseFile()
+
+	msg := "a log line longer than ten bytes"
+
+	//Each write after the first rolls the log file over
+	for i := 0; i < 3; i++ {
+		fl.WriteLogToFile(msg, loglevel.Info)
+	}
+	fl.WaitCompression()
+
+	label := c.Rollover.Label(c.Rollover.Start(c.Time.In(time.Now())))
+
+	//Numbers keep increasing when the earlier rolled over files have been compressed
+	assert.Equal(t, []string{
+		"application-test.log",
+		"application-test_" + label + "_1.log.gz",
+		"application-test_" + label + "_2.log.gz",
+	}, fileNames(t, dir))
+
+	f, err := os.Open(filepath.Join(dir, "application-test_"+label+"_1.log.gz"))
+	assert.Nil(t, err)
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	assert.Nil(t, err)
+
+	b, err := io.ReadAll(zr)
+	assert.Nil(t, err)
+	assert.Equal(t, msg+"\n", string(b))
+}
+
+func TestRegisterCodec(t *testing.T) {
+	RegisterCodec("NOP", nopCodec{})
+	defer func() {
+		codecsMu.Lock()
+		delete(codecs, "nop")
+		codecsMu.Unlock()
+	}()
+
+	dir := t.TempDir()
+	c := testConfig(dir)
+	c.DoDateRollover = false
+	c.RolloverSize = 10
+	c.Compression = "nop"
+	c.MaxBackups = 1
+
+	fl := New(func() config.KloggerConfig { return c })
+	defer fl.CloseFile()
+
+	for i := 0; i < 3; i++ {
+		fl.WriteLogToFile("a log line longer than ten bytes", loglevel.Info)
+	}
+	fl.WaitCompression()
+
+	label := c.Rollover.Label(c.Rollover.Start(c.Time.In(time.Now())))
+
+	//Compressed files count towards the retention limits
+	assert.Equal(t, []string{
+		"application-test.log",
+		"application-test_" + label + "_2.log.nop",
+	}, fileNames(t, dir))
+}
+
+func TestCompressionRetention(t *testing.T) {
+	dir := t.TempDir()
+	c := testConfig(dir)
+	c.DoDateRollover = false
+	c.RolloverSize = 10
+	c.Compression = constants.CompressionGzip
+	c.MaxBackups = 1
+
+	fl := New(func() config.KloggerConfig { return c })
+	defer fl.CloseFile()
+
+	var mu sync.Mutex
+	var errs []error
+
+	fl.SetErrorHandler(func(err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	})
+
+	//Quick rollovers start compressions while others are still pruning
+	for i := 0; i < 50; i++ {
+		fl.WriteLogToFile("a log line longer than ten bytes", loglevel.Info)
+	}
+	fl.WaitCompression()
+
+	//Files being compressed are not pruned from under their compression
+	assert.Empty(t, errs)
+
+	names := fileNames(t, dir)
+	assert.Equal(t, 2, len(names))
+	assert.Equal(t, "application-test.log", names[0])
+	assert.Equal(t, ".gz", filepath.Ext(names[1]))
+}
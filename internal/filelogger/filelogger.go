@@ -1,140 +1,436 @@
 package filelogger
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"strconv"
-	"strings"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/jon-kamis/klogger/internal/config"
-	"github.com/jon-kamis/klogger/internal/utils"
+	"github.com/jon-kamis/klogger/internal/constants"
+	"github.com/jon-kamis/klogger/pkg/loglevel"
 )
 
-// var f is the file to write logs to. Note it will be closed automatically at program termination by the garbage collector
-var f *os.File
+// Type FileLogger writes logs to a single log file and tracks its rollover state. It is safe for concurrent use
+type FileLogger struct {
+	conf     func() config.KloggerConfig
+	mu       sync.Mutex    //Guards the fields below across writes, rollovers and closes
+	f        *os.File      //The file to write logs to. Note it will be closed automatically at program termination by the garbage collector
+	dirty    bool          //Whether f has been written to since it was last synced
+	bucket   time.Time     //The start of the rollover interval the log file belongs to. Zero until the file is first opened or checked
+	stopSync chan struct{} //Closed to stop the interval sync goroutine
+	stopRoll chan struct{} //Closed to stop the rollover scheduler goroutine
+	started  bool          //Whether the first log has been written, used by RotateOnStartup
+	checked  time.Time     //When the open log file was last compared with the file at its path
+	pending  []error       //Errors raised while fl.mu is held, reported once it is released
+
+	compressing sync.WaitGroup  //Tracks rolled over files being compressed in the background
+	pruneMu     sync.Mutex      //Serializes pruning and guards inProgress. Taken after fl.mu when both are held
+	inProgress  map[string]bool //The paths of rolled over files being compressed and of their compressed copies, which pruning skips
+
+	onError atomic.Pointer[func(error)] //Receives errors which cannot be returned to a caller, such as those from rollovers and background goroutines
+}
+
+// var ErrSync is wrapped by errors from syncing the log file to disk. A log returned with it has been written to the log file
+var ErrSync = errors.New("failed to sync log file")
+
+// var std is the FileLogger used by the package level functions
+var std = New(config.GetConfig)
+
+// Function New returns a FileLogger which reads its settings from the given config function before each write
+func New(conf func() config.KloggerConfig) *FileLogger {
+	return &FileLogger{conf: conf}
+}
 
+// Function Default returns the FileLogger used by the package level functions
+func Default() *FileLogger {
+	return std
+}
+
+// Function CloseFile closes the log file of the default FileLogger
 func CloseFile() {
-	if f != nil {
-		f.Close()
-		f = nil
+	std.CloseFile()
+}
+
+// Function WriteLogToFile writes a log to file using the default FileLogger
+// m - message to log
+// l - the log level of the message, used by the onLevel sync policy
+func WriteLogToFile(msg string, l loglevel.LogLevel) error {
+	return std.WriteLogToFile(msg, l)
+}
+
+// Function SetErrorHandler sets the function receiving errors which cannot be returned to a caller, such as those from rolling the log file over, compression and background syncs. If nil they are printed to stderr
+func (fl *FileLogger) SetErrorHandler(h func(error)) {
+	if h == nil {
+		fl.onError.Store(nil)
+		return
+	}
+
+	fl.onError.Store(&h)
+}
+
+// Function reportError passes an error to the error handler, or prints it to stderr if there is none
+func (fl *FileLogger) reportError(err error) {
+	if h := fl.onError.Load(); h != nil {
+		(*h)(err)
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "[Klogger] %v\n", err)
+}
+
+// Function deferError holds an error until fl.mu is released so that the error handler can safely log. The caller must hold fl.mu
+func (fl *FileLogger) deferError(err error) {
+	fl.pending = append(fl.pending, err)
+}
+
+// Function unlock releases fl.mu and then reports the errors raised while it was held
+func (fl *FileLogger) unlock() {
+	errs := fl.pending
+	fl.pending = nil
+
+	fl.mu.Unlock()
+
+	for _, err := range errs {
+		fl.reportError(err)
+	}
+}
+
+// Function CloseFile closes the current log file. The next write will reopen it
+func (fl *FileLogger) CloseFile() {
+	fl.mu.Lock()
+	defer fl.unlock()
+
+	fl.closeFile()
+}
+
+// Function Sync syncs the log file to disk if it has been written to since it was last synced
+func (fl *FileLogger) Sync() error {
+	fl.mu.Lock()
+	defer fl.unlock()
+
+	if fl.f == nil || !fl.dirty {
+		return nil
+	}
+
+	if err := fl.f.Sync(); err != nil {
+		return fmt.Errorf("%w: %w", ErrSync, err)
+	}
+
+	fl.dirty = false
+
+	return nil
+}
+
+// Function closeFile closes the current log file and stops the interval sync and rollover scheduler goroutines. The caller must hold fl.mu
+func (fl *FileLogger) closeFile() {
+	if fl.stopSync != nil {
+		close(fl.stopSync)
+		fl.stopSync = nil
+	}
+
+	if fl.stopRoll != nil {
+		close(fl.stopRoll)
+		fl.stopRoll = nil
+	}
+
+	if fl.f != nil {
+		fl.f.Close()
+		fl.f = nil
 	}
 }
 
 // Function WriteLogToFile writes a log to file based on config settings
 // m - message to log
-func WriteLogToFile(msg string) {
+// l - the log level of the message, used by the onLevel sync policy
+// Returns an error if the log could not be written or synced. Sync errors wrap ErrSync, as the log was written. Errors from rolling the file over are passed to the error handler instead
+func (fl *FileLogger) WriteLogToFile(msg string, l loglevel.LogLevel) error {
 
-	c := config.GetConfig()
+	c := fl.conf()
 
-	_ = os.Mkdir(c.LogFileDir, os.ModePerm)
+	//Hold the lock across the rollover check and write so that no goroutine writes to a file being rolled over
+	fl.mu.Lock()
+	defer fl.unlock()
+
+	if err := os.Mkdir(c.LogFileDir, os.ModePerm); err != nil && !os.IsExist(err) {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	if !fl.started {
+		fl.started = true
+
+		if c.DoRollover && c.RotateOnStartup {
+			fl.rotateOnStartup(c)
+		}
+	}
+
+	fl.checkFileReplaced(c)
 
 	if c.DoRollover {
-		checkFileRollover(c)
+		fl.checkFileRollover(c)
+	}
+
+	if fl.f == nil {
+		if err := fl.openFile(c); err != nil {
+			return err
+		}
+	}
+
+	if _, err := fl.f.Write([]byte(msg + "\n")); err != nil {
+		return fmt.Errorf("failed to write log file: %w", err)
+	}
+
+	fl.dirty = true
+
+	return fl.syncFile(c, l)
+}
+
+// Function checkFileReplaced closes the log file if the file at its path has been deleted or replaced since it was opened, so that the next write opens the file at the path again. Runs at most once every FileCheckInterval milliseconds. The caller must hold fl.mu
+func (fl *FileLogger) checkFileReplaced(c config.KloggerConfig) {
+	if fl.f == nil || c.FileCheckInterval <= 0 {
+		return
+	}
+
+	now := time.Now()
+
+	if now.Sub(fl.checked) < time.Duration(c.FileCheckInterval)*time.Millisecond {
+		return
+	}
+
+	fl.checked = now
+
+	ofi, err := fl.f.Stat()
+
+	if err != nil {
+		return
+	}
+
+	pfi, err := os.Stat(filepath.Join(c.LogFileDir, c.LogFileName))
+
+	if err == nil && os.SameFile(ofi, pfi) {
+		return
+	}
+
+	fl.deferError(fmt.Errorf("log file %s was deleted or replaced, reopening it", c.LogFileName))
+
+	//Any file now at the path is new so its rollover interval is worked out again
+	fl.closeFile()
+	fl.bucket = time.Time{}
+}
+
+// Function openFile opens the log file for appending, creating it if needed, and starts the rollover scheduler. The caller must hold fl.mu
+func (fl *FileLogger) openFile(c config.KloggerConfig) error {
+	_ = os.Mkdir(c.LogFileDir, os.ModePerm)
+
+	f, err := os.OpenFile(filepath.Join(c.LogFileDir, c.LogFileName), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	fl.f = f
+	fl.checked = time.Now()
+
+	if fl.bucket.IsZero() {
+		fl.bucket = c.Rollover.Start(c.Time.In(time.Now()))
+	}
+
+	if c.DoRollover && c.DoDateRollover {
+		fl.startRolloverScheduler(c)
+	}
+
+	return nil
+}
+
+// Function Rotate rolls the log file over immediately, regardless of its size and rollover interval. Nothing is done if the log file is empty or does not exist
+func (fl *FileLogger) Rotate() {
+	fl.mu.Lock()
+	defer fl.unlock()
+
+	c := fl.conf()
+
+	fi, err := os.Stat(filepath.Join(c.LogFileDir, c.LogFileName))
+
+	if err != nil || fi.Size() == 0 {
+		return
+	}
+
+	bucket := fl.bucket
+
+	if bucket.IsZero() {
+		bucket = c.Rollover.Start(c.Time.In(fi.ModTime()))
 	}
 
-	if f == nil{
+	fl.renameFile(c, c.Rollover.Label(bucket))
+}
 
-		fn := fmt.Sprintf("%s/%s", c.LogFileDir, c.LogFileName)
+// Function Reopen closes the log file and opens the file at LogFileDir/LogFileName again without renaming it, for use after the file has been moved by an external tool such as logrotate
+// If the log file was not open it is left to be opened by the next write
+func (fl *FileLogger) Reopen() {
+	fl.mu.Lock()
+	defer fl.unlock()
 
-		var err error
+	if fl.f == nil {
+		return
+	}
 
-		f, err = os.OpenFile(fn, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	fl.closeFile()
 
-		if err != nil {
-			fmt.Printf("error occured %v\n", err)
-			return
+	//The reopened file may be a new file so it starts a new rollover interval
+	fl.bucket = time.Time{}
+
+	if err := fl.openFile(fl.conf()); err != nil {
+		fl.deferError(err)
+	}
+}
+
+// Function syncFile syncs the log file after a write according to the sync policy. The caller must hold fl.mu
+func (fl *FileLogger) syncFile(c config.KloggerConfig, l loglevel.LogLevel) error {
+	switch c.SyncPolicy {
+	case constants.SyncPolicyNever:
+		return nil
+	case constants.SyncPolicyInterval:
+		fl.startSyncTicker(c)
+		return nil
+	case constants.SyncPolicyOnLevel:
+		if l < c.SyncLogLevel {
+			return nil
 		}
+	}
+
+	if err := fl.f.Sync(); err != nil {
+		return fmt.Errorf("%w: %w", ErrSync, err)
+	}
+
+	fl.dirty = false
+
+	return nil
+}
+
+// Function startSyncTicker starts a goroutine syncing the log file every SyncInterval milliseconds if one is not already running. The caller must hold fl.mu
+func (fl *FileLogger) startSyncTicker(c config.KloggerConfig) {
+	if fl.stopSync != nil {
+		return
+	}
+
+	d := time.Duration(c.SyncInterval) * time.Millisecond
 
+	if d <= 0 {
+		d = constants.DefaultSyncIntervalValue * time.Millisecond
 	}
 
-	f.Write([]byte(msg + "\n"))
-	f.Sync()
+	stop := make(chan struct{})
+	fl.stopSync = stop
+
+	go func() {
+		t := time.NewTicker(d)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-t.C:
+				if err := fl.Sync(); err != nil {
+					fl.reportError(err)
+				}
+			}
+		}
+	}()
 }
 
-// Function checkFileRollover determines if a file should be rolled over prior to writing to it
-func checkFileRollover(c config.KloggerConfig) {
-	
+// Function checkFileRollover determines if a file should be rolled over prior to writing to it. The caller must hold fl.mu
+func (fl *FileLogger) checkFileRollover(c config.KloggerConfig) {
+
 	var fi os.FileInfo
 	var err error
 
-	if f != nil {
-		fi, err = f.Stat()
+	if fl.f != nil {
+		fi, err = fl.f.Stat()
 	} else {
-		fi, err = os.Stat(fmt.Sprintf("%s/%s", c.LogFileDir, c.LogFileName))
+		fi, err = os.Stat(filepath.Join(c.LogFileDir, c.LogFileName))
 	}
 
 	if err != nil {
 		return
 	}
 
-	//First check date Rollover
-	if c.DoDateRollover && fi.ModTime().Before(utils.GetStartOfDay(time.Now())) {
+	//Intervals are taken in the time zone used for log timestamps
+	now := c.Time.In(time.Now())
+
+	//A file left by an earlier run belongs to the interval it was last written in
+	if fl.bucket.IsZero() {
+		fl.bucket = c.Rollover.Start(c.Time.In(fi.ModTime()))
+	}
 
-		dtStr := fi.ModTime().Format("2006-01-02")
-		renameFile(c, dtStr)
+	//First check time based Rollover
+	if c.DoDateRollover && !now.Before(c.Rollover.Next(fl.bucket)) {
+
+		fl.renameFile(c, c.Rollover.Label(fl.bucket))
 
 		//Return to prevent double rolling over
 		return
 	}
 
 	if c.DoSizeRollover && fi.Size() > c.RolloverSize {
-		renameFile(c, time.Now().Format("2006-01-02"))
+		fl.renameFile(c, c.Rollover.Label(c.Rollover.Start(now)))
 	}
 }
 
-func renameFile(c config.KloggerConfig, s string) {
+// Function renameFile closes the current log file and renames it to the next rollover file name for the interval label. The caller must hold fl.mu
+func (fl *FileLogger) renameFile(c config.KloggerConfig, s string) {
 	//Original File Name
-	ofn := fmt.Sprintf("%s/%s", c.LogFileDir, c.LogFileName)
+	ofn := filepath.Join(c.LogFileDir, c.LogFileName)
+
+	fnum, err := getHighestFileNumForDate(c, s)
+
+	if err != nil {
+		fl.deferError(fmt.Errorf("failed to roll over log file: %w", err))
+		return
+	}
 
 	//New File Name
-	fp := strings.Split(ofn, ".") //File Parts, 0 -> File Path and Name, 1 -> File Extension
+	nfn := filepath.Join(c.LogFileDir, c.RolloverName.Name(c.LogFileName, s, fnum+1))
 
-	fnum := getHighestFileNumForDate(c, s)
-	var nfn string
+	//Close the current file and set its value to nil. This will cause the next log to generate a new file in the current interval
+	fl.closeFile()
+	fl.bucket = time.Time{}
 
-	if fnum >= 0 {
-		nfn = fmt.Sprintf("%s_%s_%d.%s", fp[0], s, fnum+1, fp[1])
-	} else {
-		fmt.Printf("[Klogger] failed to rename file due to error")
+	if err := os.Rename(ofn, nfn); err != nil {
+		fl.deferError(fmt.Errorf("failed to roll over log file: %w", err))
 		return
 	}
 
-	//Close the current file and set its value to nil. This will cause the next log to generate a new file
-	f.Close()
-	f = nil
-
-	os.Rename(ofn, nfn)
+	//When compressing, the retention limits are applied once the compressed file has been written
+	if !fl.compressRolledFile(c, nfn) {
+		fl.pruneRolledFiles(c, fl.deferError)
+	}
 }
 
-// Function getHighestFileNumForDate returns the highest log file number for the given date
+// Function getHighestFileNumForDate returns the highest log file number for the given rollover interval, or 0 if there are none. Returns an error if the log directory cannot be read
 // c - the Klogger Config required for loading files
-// s - a string representing the date to check for
-func getHighestFileNumForDate(c config.KloggerConfig, s string) int {
+// s - the label of the rollover interval to check for
+func getHighestFileNumForDate(c config.KloggerConfig, s string) (int, error) {
 	files, err := os.ReadDir(c.LogFileDir)
 
 	if err != nil {
-		panic("[Klogger] failed to read directory " + c.LogFileDir)
+		return 0, err
 	}
 
 	highestNum := 0
+	m := rolledFileMatcher(c)
 
 	for _, file := range files {
 
-		if strings.Contains(file.Name(), s) {
-			name := file.Name()
-			name = name[:strings.IndexByte(name, '.')]
-			num, err := strconv.Atoi(strings.Split(name, "_")[2])
-
-			if err != nil {
-				fmt.Printf("error occured: %v\n", err)
-				return -1
-			}
+		//Compressed files are matched too so that numbers are not reused while or after they are compressed
+		date, num, ok := m.Match(file.Name())
 
-			if num > highestNum {
-				highestNum = num
-			}
+		if ok && date == s && num > highestNum {
+			highestNum = num
 		}
 	}
-	return highestNum
+	return highestNum, nil
 }
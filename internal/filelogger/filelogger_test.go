@@ -0,0 +1,318 @@
+package filelogger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jon-kamis/klogger/internal/config"
+	"github.com/jon-kamis/klogger/internal/constants"
+	"github.com/jon-kamis/klogger/pkg/loglevel"
+	"github.com/stretchr/testify/assert"
+)
+
+func testConfig(dir string) config.KloggerConfig {
+	c := config.Default()
+	c.LogFileDir = dir
+	c.LogFileName = "application-test.log"
+
+	return c
+}
+
+// Function readAllLines returns every line written to every file in dir
+func readAllLines(t *testing.T, dir string) []string {
+	files, err := os.ReadDir(dir)
+	assert.Nil(t, err)
+
+	var lines []string
+
+	for _, file := range files {
+		b, err := os.ReadFile(filepath.Join(dir, file.Name()))
+		assert.Nil(t, err)
+
+		for _, l := range strings.Split(string(b), "\n") {
+			if l != "" {
+				lines = append(lines, l)
+			}
+		}
+	}
+
+	return lines
+}
+
+func TestWriteLogToFileConcurrentRollover(t *testing.T) {
+	dir := t.TempDir()
+	c := testConfig(dir)
+	c.RolloverSize = 512
+
+	fl := New(func() config.KloggerConfig { return c })
+	defer fl.CloseFile()
+
+	goroutines := 100
+	writes := 50
+	msg := "concurrent log line"
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < writes; j++ {
+				fl.WriteLogToFile(msg, loglevel.Info)
+			}
+		}()
+	}
+
+	wg.Wait()
+	fl.CloseFile()
+
+	//Every line should have been written exactly once and in full across all rolled over files
+	lines := readAllLines(t, dir)
+	assert.Equal(t, goroutines*writes, len(lines))
+
+	for _, l := range lines {
+		assert.Equal(t, msg, l)
+	}
+
+	files, err := os.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.Greater(t, len(files), 1)
+}
+
+func TestCloseFileConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	c := testConfig(dir)
+
+	fl := New(func() config.KloggerConfig { return c })
+
+	var wg sync.WaitGroup
+
+	//Closing while other goroutines write should never lose a line
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			fl.WriteLogToFile("line", loglevel.Info)
+		}()
+		go func() {
+			defer wg.Done()
+			fl.CloseFile()
+		}()
+	}
+
+	wg.Wait()
+	fl.CloseFile()
+
+	assert.Equal(t, 50, len(readAllLines(t, dir)))
+}
+
+func TestSyncPolicy(t *testing.T) {
+	c := testConfig(t.TempDir())
+	fl := New(func() config.KloggerConfig { return c })
+	defer fl.CloseFile()
+
+	//Always syncs after every write
+	c.SyncPolicy = constants.SyncPolicyAlways
+	fl.WriteLogToFile("line", loglevel.Info)
+	assert.False(t, fl.dirty)
+
+	//Never leaves the file unsynced
+	c.SyncPolicy = constants.SyncPolicyNever
+	fl.WriteLogToFile("line", loglevel.Error)
+	assert.True(t, fl.dirty)
+	assert.Nil(t, fl.stopSync)
+
+	//OnLevel only syncs at or above the sync log level
+	c.SyncPolicy = constants.SyncPolicyOnLevel
+	c.SyncLogLevel = loglevel.Warn
+	fl.WriteLogToFile("line", loglevel.Info)
+	assert.True(t, fl.dirty)
+	fl.WriteLogToFile("line", loglevel.Warn)
+	assert.False(t, fl.dirty)
+
+	//Sync syncs any unsynced writes
+	c.SyncPolicy = constants.SyncPolicyNever
+	fl.WriteLogToFile("line", loglevel.Info)
+	fl.Sync()
+	assert.False(t, fl.dirty)
+}
+
+func TestSyncPolicyInterval(t *testing.T) {
+	c := testConfig(t.TempDir())
+	c.SyncPolicy = constants.SyncPolicyInterval
+	c.SyncInterval = 10
+
+	fl := New(func() config.KloggerConfig { return c })
+
+	fl.WriteLogToFile("line", loglevel.Info)
+
+	fl.mu.Lock()
+	assert.NotNil(t, fl.stopSync)
+	fl.mu.Unlock()
+
+	//The background ticker should sync the file
+	assert.Eventually(t, func() bool {
+		fl.mu.Lock()
+		defer fl.mu.Unlock()
+		return !fl.dirty
+	}, time.Second, 5*time.Millisecond)
+
+	//Closing the file stops the ticker
+	fl.CloseFile()
+	assert.Nil(t, fl.stopSync)
+}
+
+func TestIntervalRollover(t *testing.T) {
+	dir := t.TempDir()
+	c := testConfig(dir)
+	c.DoSizeRollover = false
+	c.RolloverInterval = "hour"
+	c.Rollover = config.ParseRolloverInterval(c.RolloverInterval)
+
+	//A log file last written two hours ago belongs to an earlier interval
+	fn := filepath.Join(dir, c.LogFileName)
+	assert.Nil(t, os.WriteFile(fn, []byte("old\n"), 0644))
+
+	old := time.Now().Add(-2 * time.Hour)
+	assert.Nil(t, os.Chtimes(fn, old, old))
+
+	fl := New(func() config.KloggerConfig { return c })
+	defer fl.CloseFile()
+
+	fl.WriteLogToFile("new", loglevel.Info)
+	fl.WriteLogToFile("new", loglevel.Info)
+
+	label := c.Rollover.Label(c.Rollover.Start(c.Time.In(old)))
+
+	b, err := os.ReadFile(filepath.Join(dir, "application-test_"+label+"_1.log"))
+	assert.Nil(t, err)
+	assert.Equal(t, "old\n", string(b))
+
+	//The new file stays open until the end of the current interval
+	b, err = os.ReadFile(fn)
+	assert.Nil(t, err)
+	assert.Equal(t, "new\nnew\n", string(b))
+}
+
+func TestRolloverNaming(t *testing.T) {
+	tests := []struct {
+		name    string
+		dir     string
+		file    string
+		pattern string
+		want    string
+	}{
+		{name: "dot in directory", dir: "./logs.d", file: "application.log", pattern: "{base}_{date}_{n}{ext}", want: "application_%s_2.log"},
+		{name: "underscores", dir: "logs", file: "my_app_name.log", pattern: "{base}_{date}_{n}{ext}", want: "my_app_name_%s_2.log"},
+		{name: "multiple dots", dir: "logs", file: "my.app.log", pattern: "{base}_{date}_{n}{ext}", want: "my.app_%s_2.log"},
+		{name: "no extension", dir: "logs", file: "application", pattern: "{base}_{date}_{n}{ext}", want: "application_%s_2"},
+		{name: "custom pattern", dir: "logs", file: "my_app.log", pattern: "{base}-{date}.{n}{ext}", want: "my_app-%s.2.log"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			//Run from a temp directory so that relative log directories are created inside it
+			wd, err := os.Getwd()
+			assert.Nil(t, err)
+			assert.Nil(t, os.Chdir(t.TempDir()))
+			defer os.Chdir(wd)
+
+			c := testConfig(tt.dir)
+			c.LogFileName = tt.file
+			c.DoDateRollover = false
+			c.RolloverSize = 10
+			c.RolloverNamePattern = tt.pattern
+			c.RolloverName = config.ParseRolloverNamePattern(tt.pattern)
+
+			fl := New(func() config.KloggerConfig { return c })
+
+			//Three writes roll the log file over twice
+			for i := 0; i < 3; i++ {
+				fl.WriteLogToFile("a log line longer than ten bytes", loglevel.Info)
+			}
+			fl.CloseFile()
+
+			label := c.Rollover.Label(c.Rollover.Start(c.Time.In(time.Now())))
+
+			_, err = os.Stat(filepath.Join(tt.dir, fmt.Sprintf(tt.want, label)))
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestFileReplaced(t *testing.T) {
+	dir := t.TempDir()
+	c := testConfig(dir)
+	c.FileCheckInterval = 1
+
+	fl := New(func() config.KloggerConfig { return c })
+	defer fl.CloseFile()
+
+	fn := filepath.Join(dir, c.LogFileName)
+
+	fl.WriteLogToFile("first", loglevel.Info)
+
+	//A deleted log file is recreated
+	assert.Nil(t, os.Remove(fn))
+	time.Sleep(5 * time.Millisecond)
+	fl.WriteLogToFile("after delete", loglevel.Info)
+
+	b, err := os.ReadFile(fn)
+	assert.Nil(t, err)
+	assert.Equal(t, "after delete\n", string(b))
+
+	//A replaced log file is written to instead of the old one
+	assert.Nil(t, os.Rename(fn, fn+".old"))
+	assert.Nil(t, os.WriteFile(fn, []byte("replacement\n"), 0644))
+	time.Sleep(5 * time.Millisecond)
+	fl.WriteLogToFile("after replace", loglevel.Info)
+
+	b, err = os.ReadFile(fn)
+	assert.Nil(t, err)
+	assert.Equal(t, "replacement\nafter replace\n", string(b))
+
+	b, err = os.ReadFile(fn + ".old")
+	assert.Nil(t, err)
+	assert.Equal(t, "after delete\n", string(b))
+
+	//The check is skipped when disabled
+	c.FileCheckInterval = 0
+	assert.Nil(t, os.Remove(fn))
+	time.Sleep(5 * time.Millisecond)
+	fl.WriteLogToFile("unlinked", loglevel.Info)
+
+	_, err = os.Stat(fn)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestWriteLogToFileErrors(t *testing.T) {
+	//A file where the log directory should be stops the log file being opened
+	dir := filepath.Join(t.TempDir(), "logs")
+	assert.Nil(t, os.WriteFile(dir, nil, 0644))
+
+	c := testConfig(dir)
+	fl := New(func() config.KloggerConfig { return c })
+	defer fl.CloseFile()
+
+	assert.NotNil(t, fl.WriteLogToFile("message", loglevel.Info))
+
+	//Errors from rolling the log file over are passed to the error handler
+	c = testConfig(t.TempDir())
+	c.DoDateRollover = false
+	c.RolloverSize = 10
+	c.Compression = "unknown"
+
+	var errs []error
+	fl.SetErrorHandler(func(err error) { errs = append(errs, err) })
+
+	assert.Nil(t, fl.WriteLogToFile("a log line longer than ten bytes", loglevel.Info))
+	assert.Nil(t, fl.WriteLogToFile("a log line longer than ten bytes", loglevel.Info))
+
+	assert.Equal(t, 1, len(errs))
+	assert.True(t, strings.Contains(errs[0].Error(), `unknown compression codec "unknown"`))
+}
@@ -0,0 +1,145 @@
+package filelogger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/jon-kamis/klogger/internal/config"
+	"github.com/jon-kamis/klogger/internal/rollname"
+)
+
+// Type rolledFile is a rolled over log file found in the log file directory
+type rolledFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+	date    string //The label of the rollover interval from the file name
+	n       int    //The number of the file within its interval from the file name
+}
+
+// Function retentionEnabled returns true if any retention limit is set
+func retentionEnabled(c config.KloggerConfig) bool {
+	return c.MaxBackups > 0 || c.MaxAgeDays > 0 || c.MaxTotalSize > 0
+}
+
+// Function rolledFileMatcher returns a Matcher for the names klogger gives rolled over log files, including those compressed by a registered Codec
+func rolledFileMatcher(c config.KloggerConfig) rollname.Matcher {
+	return c.RolloverName.Matcher(c.LogFileName, codecExtensions()...)
+}
+
+// Function listRolledFiles returns the rolled over log files in the log file directory, newest first
+func listRolledFiles(c config.KloggerConfig) ([]rolledFile, error) {
+	entries, err := os.ReadDir(c.LogFileDir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	m := rolledFileMatcher(c)
+
+	var files []rolledFile
+
+	for _, e := range entries {
+		if !e.Type().IsRegular() {
+			continue
+		}
+
+		date, n, ok := m.Match(e.Name())
+
+		if !ok {
+			continue
+		}
+
+		fi, err := e.Info()
+
+		if err != nil {
+			continue
+		}
+
+		files = append(files, rolledFile{
+			path:    filepath.Join(c.LogFileDir, e.Name()),
+			size:    fi.Size(),
+			modTime: fi.ModTime(),
+			date:    date,
+			n:       n,
+		})
+	}
+
+	//Files rolled over within the same timestamp resolution are ordered by their interval and number, so that _10 is newer than _9
+	sort.Slice(files, func(i, j int) bool {
+		a, b := files[i], files[j]
+
+		switch {
+		case !a.modTime.Equal(b.modTime):
+			return a.modTime.After(b.modTime)
+		case a.date != b.date:
+			return a.date > b.date
+		}
+
+		return a.n > b.n
+	})
+
+	return files, nil
+}
+
+// Function filesToPrune returns the rolled over files which exceed the retention limits. It does not modify any files
+// files - the rolled over files, newest first
+// now - the time file ages are measured from
+func filesToPrune(c config.KloggerConfig, files []rolledFile, now time.Time) []rolledFile {
+	var prune []rolledFile
+	var total int64
+
+	cutoff := now.AddDate(0, 0, -c.MaxAgeDays)
+
+	for i, f := range files {
+		total += f.size
+
+		switch {
+		case c.MaxBackups > 0 && i >= c.MaxBackups:
+		case c.MaxAgeDays > 0 && f.modTime.Before(cutoff):
+		case c.MaxTotalSize > 0 && total > c.MaxTotalSize:
+		default:
+			continue
+		}
+
+		prune = append(prune, f)
+	}
+
+	return prune
+}
+
+// Function pruneRolledFiles deletes the rolled over files which exceed the retention limits. Files being compressed are neither counted nor deleted, and are pruned once their compression finishes
+// report - receives any errors. Must be fl.deferError when the caller holds fl.mu
+func (fl *FileLogger) pruneRolledFiles(c config.KloggerConfig, report func(error)) {
+	if !retentionEnabled(c) {
+		return
+	}
+
+	//Pruning from several compression goroutines at once could remove files another is still counting
+	fl.pruneMu.Lock()
+	defer fl.pruneMu.Unlock()
+
+	files, err := listRolledFiles(c)
+
+	if err != nil {
+		report(fmt.Errorf("failed to list rolled over log files: %w", err))
+		return
+	}
+
+	done := files[:0]
+
+	for _, f := range files {
+		if !fl.inProgress[f.path] {
+			done = append(done, f)
+		}
+	}
+
+	for _, f := range filesToPrune(c, done, time.Now()) {
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			report(fmt.Errorf("failed to remove rolled over log file: %w", err))
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package filelogger
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/jon-kamis/klogger/internal/config"
+	"github.com/jon-kamis/klogger/pkg/loglevel"
+	"github.com/stretchr/testify/assert"
+)
+
+// Function writeAgedFile writes a file of the given size to dir with a modification time age before now
+func writeAgedFile(t *testing.T, dir string, name string, size int, age time.Duration) {
+	fn := filepath.Join(dir, name)
+	assert.Nil(t, os.WriteFile(fn, make([]byte, size), 0644))
+
+	mt := time.Now().Add(-age)
+	assert.Nil(t, os.Chtimes(fn, mt, mt))
+}
+
+// Function fileNames returns the sorted names of the files in dir
+func fileNames(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	assert.Nil(t, err)
+
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func TestRolledFileMatcher(t *testing.T) {
+	m := rolledFileMatcher(testConfig(t.TempDir()))
+
+	for _, n := range []string{"application-test_2024-02-15_1.log", "application-test_2024-02-15T10_12.log", "application-test_2024-W07_3.log", "application-test_2024-02-15_2.log.gz"} {
+		_, _, ok := m.Match(n)
+		assert.True(t, ok, n)
+	}
+
+	for _, n := range []string{"application-test.log", "application-test_2024-02-15_1.log.bak", "other_2024-02-15_1.log", "application-test_2024-02-15_x.log", "notes.txt"} {
+		_, _, ok := m.Match(n)
+		assert.False(t, ok, n)
+	}
+}
+
+func TestFilesToPrune(t *testing.T) {
+	now := time.Date(2024, 2, 15, 12, 0, 0, 0, time.UTC)
+
+	//Newest first
+	files := []rolledFile{
+		{path: "a", size: 100, modTime: now.Add(-1 * time.Hour)},
+		{path: "b", size: 100, modTime: now.Add(-25 * time.Hour)},
+		{path: "c", size: 100, modTime: now.Add(-49 * time.Hour)},
+		{path: "d", size: 100, modTime: now.Add(-73 * time.Hour)},
+	}
+
+	paths := func(fs []rolledFile) []string {
+		var p []string
+		for _, f := range fs {
+			p = append(p, f.path)
+		}
+		return p
+	}
+
+	tests := []struct {
+		name  string
+		conf  func(c *config.KloggerConfig)
+		prune []string
+	}{
+		{"no limits", func(c *config.KloggerConfig) {}, nil},
+		{"max backups", func(c *config.KloggerConfig) { c.MaxBackups = 2 }, []string{"c", "d"}},
+		{"max age", func(c *config.KloggerConfig) { c.MaxAgeDays = 2 }, []string{"c", "d"}},
+		{"max total size", func(c *config.KloggerConfig) { c.MaxTotalSize = 250 }, []string{"c", "d"}},
+		{"combined", func(c *config.KloggerConfig) { c.MaxBackups = 3; c.MaxAgeDays = 1 }, []string{"b", "c", "d"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testConfig("")
+			tt.conf(&c)
+
+			assert.Equal(t, tt.prune, paths(filesToPrune(c, files, now)))
+		})
+	}
+}
+
+func TestRetention(t *testing.T) {
+	dir := t.TempDir()
+	c := testConfig(dir)
+	c.DoDateRollover = false
+	c.RolloverSize = 10
+	c.MaxBackups = 2
+
+	day := 24 * time.Hour
+	writeAgedFile(t, dir, "application-test_2024-02-12_1.log", 10, 3*day)
+	writeAgedFile(t, dir, "application-test_2024-02-13_1.log", 10, 2*day)
+	writeAgedFile(t, dir, "application-test_2024-02-14_1.log", 10, day)
+
+	//Files which do not follow the rolled over naming scheme are never removed
+	writeAgedFile(t, dir, "application-test.log.bak", 10, 10*day)
+	writeAgedFile(t, dir, "other_2024-02-12_1.log", 10, 10*day)
+
+	fl := New(func() config.KloggerConfig { return c })
+	defer fl.CloseFile()
+
+	//Write past the rollover size to roll over the log file
+	fl.WriteLogToFile("a log line longer than ten bytes", loglevel.Info)
+	fl.WriteLogToFile("a log line longer than ten bytes", loglevel.Info)
+
+	today := c.Rollover.Label(c.Rollover.Start(c.Time.In(time.Now())))
+
+	assert.Equal(t, []string{
+		"application-test.log",
+		"application-test.log.bak",
+		"application-test_2024-02-14_1.log",
+		"application-test_" + today + "_1.log",
+		"other_2024-02-12_1.log",
+	}, fileNames(t, dir))
+}
+
+func TestRetentionEqualModTimes(t *testing.T) {
+	dir := t.TempDir()
+	c := testConfig(dir)
+	c.MaxBackups = 2
+
+	//Files rolled over within the same second share a modification time on filesystems with coarse timestamps
+	mt := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	for _, name := range []string{
+		"application-test_2024-02-14_12.log",
+		"application-test_2024-02-15_9.log",
+		"application-test_2024-02-15_10.log",
+		"application-test_2024-02-15_2.log",
+	} {
+		fn := filepath.Join(dir, name)
+		assert.Nil(t, os.WriteFile(fn, make([]byte, 10), 0644))
+		assert.Nil(t, os.Chtimes(fn, mt, mt))
+	}
+
+	files, err := listRolledFiles(c)
+	assert.Nil(t, err)
+
+	var names []string
+	for _, f := range files {
+		names = append(names, filepath.Base(f.path))
+	}
+
+	//Ties are broken by interval and then by number, newest first
+	assert.Equal(t, []string{
+		"application-test_2024-02-15_10.log",
+		"application-test_2024-02-15_9.log",
+		"application-test_2024-02-15_2.log",
+		"application-test_2024-02-14_12.log",
+	}, names)
+
+	fl := New(func() config.KloggerConfig { return c })
+	fl.pruneRolledFiles(c, fl.reportError)
+
+	assert.Equal(t, []string{
+		"application-test_2024-02-15_10.log",
+		"application-test_2024-02-15_9.log",
+	}, fileNames(t, dir))
+}
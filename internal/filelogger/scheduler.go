@@ -0,0 +1,79 @@
+package filelogger
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jon-kamis/klogger/internal/config"
+)
+
+// Function startRolloverScheduler starts a goroutine rolling the log file over at the end of its rollover interval, even if nothing is written, if one is not already running. The caller must hold fl.mu
+func (fl *FileLogger) startRolloverScheduler(c config.KloggerConfig) {
+	if fl.stopRoll != nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	fl.stopRoll = stop
+
+	go fl.runRolloverScheduler(stop, c.Rollover.Next(fl.bucket))
+}
+
+// Function runRolloverScheduler waits for each interval boundary and rolls the log file over until stop is closed or the log file is closed
+// next - the first boundary to wait for
+func (fl *FileLogger) runRolloverScheduler(stop chan struct{}, next time.Time) {
+	for {
+		t := time.NewTimer(time.Until(next))
+
+		select {
+		case <-stop:
+			t.Stop()
+			return
+		case <-t.C:
+		}
+
+		if next = fl.rolloverIfDue(stop); next.IsZero() {
+			return
+		}
+	}
+}
+
+// Function rolloverIfDue rolls the log file over if its interval has ended. Returns the next boundary to wait for, or the zero time if the scheduler should stop
+// stop - the stop channel of the calling scheduler, used to detect that it has been replaced
+func (fl *FileLogger) rolloverIfDue(stop chan struct{}) time.Time {
+	fl.mu.Lock()
+	defer fl.unlock()
+
+	//The log file was closed or rolled over since the scheduler started
+	if fl.f == nil || fl.stopRoll != stop {
+		return time.Time{}
+	}
+
+	c := fl.conf()
+
+	if !c.DoRollover || !c.DoDateRollover {
+		return time.Time{}
+	}
+
+	fl.checkFileRollover(c)
+
+	//Rolling over closes the log file and stops the scheduler. The next write opens a new file and restarts it
+	if fl.f == nil {
+		return time.Time{}
+	}
+
+	return c.Rollover.Next(fl.bucket)
+}
+
+// Function rotateOnStartup rolls over a log file left by an earlier run so that this run starts a new file. The caller must hold fl.mu
+func (fl *FileLogger) rotateOnStartup(c config.KloggerConfig) {
+	fi, err := os.Stat(filepath.Join(c.LogFileDir, c.LogFileName))
+
+	if err != nil || fi.Size() == 0 {
+		return
+	}
+
+	//The file is named after the interval it was last written in
+	fl.renameFile(c, c.Rollover.Label(c.Rollover.Start(c.Time.In(fi.ModTime()))))
+}
@@ -0,0 +1,75 @@
+package filelogger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jon-kamis/klogger/internal/config"
+	"github.com/jon-kamis/klogger/pkg/loglevel"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRolloverScheduler(t *testing.T) {
+	dir := t.TempDir()
+	c := testConfig(dir)
+	c.DoSizeRollover = false
+	c.RolloverInterval = "@every 1s"
+	c.Rollover = config.ParseRolloverInterval(c.RolloverInterval)
+
+	fl := New(func() config.KloggerConfig { return c })
+	defer fl.CloseFile()
+
+	fl.WriteLogToFile("idle", loglevel.Info)
+
+	//The log file is rolled over at the end of its interval without another write
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(dir, c.LogFileName))
+		return os.IsNotExist(err)
+	}, 3*time.Second, 50*time.Millisecond)
+
+	lines := readAllLines(t, dir)
+	assert.Equal(t, []string{"idle"}, lines)
+
+	names := fileNames(t, dir)
+	assert.Equal(t, 1, len(names))
+
+	_, n, ok := rolledFileMatcher(c).Match(names[0])
+	assert.True(t, ok, names[0])
+	assert.Equal(t, 1, n)
+
+	//Closing the log file stops the scheduler
+	fl.WriteLogToFile("closed", loglevel.Info)
+	fl.CloseFile()
+
+	time.Sleep(1500 * time.Millisecond)
+	_, err := os.Stat(filepath.Join(dir, c.LogFileName))
+	assert.Nil(t, err)
+}
+
+func TestRotateOnStartup(t *testing.T) {
+	dir := t.TempDir()
+	c := testConfig(dir)
+	c.RotateOnStartup = true
+
+	//A log file left by an earlier run
+	fn := filepath.Join(dir, c.LogFileName)
+	assert.Nil(t, os.WriteFile(fn, []byte("earlier run\n"), 0644))
+
+	fl := New(func() config.KloggerConfig { return c })
+	defer fl.CloseFile()
+
+	fl.WriteLogToFile("this run", loglevel.Info)
+	fl.WriteLogToFile("this run", loglevel.Info)
+
+	b, err := os.ReadFile(fn)
+	assert.Nil(t, err)
+	assert.Equal(t, "this run\nthis run\n", string(b))
+
+	label := c.Rollover.Label(c.Rollover.Start(c.Time.In(time.Now())))
+
+	b, err = os.ReadFile(filepath.Join(dir, "application-test_"+label+"_1.log"))
+	assert.Nil(t, err)
+	assert.Equal(t, "earlier run\n", string(b))
+}
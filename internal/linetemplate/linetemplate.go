@@ -0,0 +1,98 @@
+// Package linetemplate parses the templates used to lay out each line of text logs
+package linetemplate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Tokens which can be used in a template, written between braces
+const (
+	Time      = "time"      //The time the log was written
+	Level     = "level"     //The log level
+	Method    = "method"    //The method name
+	File      = "file"      //The base name of the calling file
+	Line      = "line"      //The line number in the calling file
+	Caller    = "caller"    //The calling file and line number as file:line
+	Goroutine = "goroutine" //The id of the goroutine which wrote the log
+	Host      = "host"      //The host name of the machine
+	PID       = "pid"       //The process id
+	Msg       = "msg"       //A single line of the message
+	Fields    = "fields"    //The fields of the log, each preceded by a space
+)
+
+var tokens = map[string]bool{
+	Time:      true,
+	Level:     true,
+	Method:    true,
+	File:      true,
+	Line:      true,
+	Caller:    true,
+	Goroutine: true,
+	Host:      true,
+	PID:       true,
+	Msg:       true,
+	Fields:    true,
+}
+
+// Type Segment is a part of a template. Either Token or Text is set
+type Segment struct {
+	Token string //The name of a token to fill in
+	Text  string //Literal text to write as it is
+}
+
+// Type Template is a parsed line template
+type Template []Segment
+
+// Function Parse parses a template made of literal text and tokens written between braces, such as "{time} [{level}] {msg}"
+// Returns an error if the template is empty, a brace is not closed or a token is unknown
+func Parse(s string) (Template, error) {
+	if s == "" {
+		return nil, fmt.Errorf("template is empty")
+	}
+
+	var t Template
+
+	for s != "" {
+		i := strings.IndexByte(s, '{')
+
+		if i < 0 {
+			t = append(t, Segment{Text: s})
+			break
+		}
+
+		if i > 0 {
+			t = append(t, Segment{Text: s[:i]})
+		}
+
+		j := strings.IndexByte(s[i:], '}')
+
+		if j < 0 {
+			return nil, fmt.Errorf("unclosed '{' before %q", s[i:])
+		}
+
+		name := s[i+1 : i+j]
+
+		if !tokens[name] {
+			return nil, fmt.Errorf("unknown token {%s}", name)
+		}
+
+		t = append(t, Segment{Token: name})
+		s = s[i+j+1:]
+	}
+
+	return t, nil
+}
+
+// Function Uses returns true if the template contains any of the given tokens
+func (t Template) Uses(names ...string) bool {
+	for _, sg := range t {
+		for _, n := range names {
+			if sg.Token == n {
+				return true
+			}
+		}
+	}
+
+	return false
+}
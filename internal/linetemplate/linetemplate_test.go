@@ -0,0 +1,63 @@
+package linetemplate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		want     Template
+		wantErr  bool
+	}{
+		{
+			name:     "default",
+			template: "{time} {level} {method} {msg}{fields}",
+			want: Template{
+				{Token: Time}, {Text: " "}, {Token: Level}, {Text: " "}, {Token: Method}, {Text: " "}, {Token: Msg}, {Token: Fields},
+			},
+		},
+		{
+			name:     "literal text",
+			template: "[{level}] pid={pid} - {msg}!",
+			want: Template{
+				{Text: "["}, {Token: Level}, {Text: "] pid="}, {Token: PID}, {Text: " - "}, {Token: Msg}, {Text: "!"},
+			},
+		},
+		{
+			name:     "text only",
+			template: "no tokens",
+			want:     Template{{Text: "no tokens"}},
+		},
+		{name: "empty", template: "", wantErr: true},
+		{name: "unknown token", template: "{time} {user}", wantErr: true},
+		{name: "unclosed brace", template: "{time} {msg", wantErr: true},
+		{name: "empty token", template: "{}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.template)
+
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestUses(t *testing.T) {
+	tmpl, err := Parse("{time} {caller} {msg}")
+	assert.Nil(t, err)
+
+	assert.True(t, tmpl.Uses(Caller))
+	assert.True(t, tmpl.Uses(File, Line, Caller))
+	assert.False(t, tmpl.Uses(Goroutine))
+}
@@ -3,6 +3,8 @@ package properties
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/jon-kamis/klogger/internal/constants"
 	"github.com/jon-kamis/klogger/pkg/loglevel"
@@ -23,18 +25,44 @@ type Property struct {
 
 // Type KloggerProperties is a struct holding properties for the application
 type KloggerProperties struct {
-	PropFileName    Property
-	LogFileName     Property
-	LogFileDir      Property
-	DoRollover      Property
-	DoSizeRollover  Property
-	DoDateRollover  Property
-	RolloverSize    Property
-	LogLevel        Property
-	LogFileLevel    Property
-	EnterLogLevel   Property
-	ExitLogLevel    Property
-	DoEnterExitLogs Property
+	PropFileName        Property
+	LogFileName         Property
+	LogFileDir          Property
+	DoRollover          Property
+	DoSizeRollover      Property
+	DoDateRollover      Property
+	RolloverSize        Property
+	LogLevel            Property
+	LogFileLevel        Property
+	EnterLogLevel       Property
+	ExitLogLevel        Property
+	DoEnterExitLogs     Property
+	LogFormat           Property
+	LogFileFormat       Property
+	DoAsync             Property
+	AsyncQueueSize      Property
+	AsyncOverflowPolicy Property
+	SyncPolicy          Property
+	SyncInterval        Property
+	SyncLogLevel        Property
+	SlowExitThreshold   Property
+	SlowExitLogLevel    Property
+	CallerSkip          Property
+	StderrLevel         Property
+	ConsoleColor        Property
+	LogTemplate         Property
+	LogTimeFormat       Property
+	LogTimeZone         Property
+	LogTimePrecision    Property
+	RolloverInterval    Property
+	MaxBackups          Property
+	MaxAgeDays          Property
+	MaxTotalSize        Property
+	Compression         Property
+	RolloverNamePattern Property
+	RotateOnStartup     Property
+	FileCheckInterval   Property
+	FileErrorPolicy     Property
 }
 
 type Number interface {
@@ -69,7 +97,7 @@ var DefaultProperties = KloggerProperties{
 	},
 	RolloverSize: Property{
 		Name:  constants.RolloverSize,
-		Value: constants.DefaultRolloverSize,
+		Value: int64(constants.DefaultRolloverSize),
 	},
 	LogLevel: Property{
 		Name:  constants.LogLevel,
@@ -91,6 +119,110 @@ var DefaultProperties = KloggerProperties{
 		Name:  constants.DoEnterExitLogs,
 		Value: constants.DefaultDoEnterExitLogs,
 	},
+	LogFormat: Property{
+		Name:  constants.LogFormat,
+		Value: constants.DefaultLogFormatValue,
+	},
+	LogFileFormat: Property{
+		Name:  constants.LogFileFormat,
+		Value: constants.DefaultLogFileFormatValue,
+	},
+	DoAsync: Property{
+		Name:  constants.DoAsync,
+		Value: constants.DefaultDoAsyncValue,
+	},
+	AsyncQueueSize: Property{
+		Name:  constants.AsyncQueueSize,
+		Value: constants.DefaultAsyncQueueSizeValue,
+	},
+	AsyncOverflowPolicy: Property{
+		Name:  constants.AsyncOverflowPolicy,
+		Value: constants.DefaultAsyncOverflowPolicyValue,
+	},
+	SyncPolicy: Property{
+		Name:  constants.SyncPolicy,
+		Value: constants.DefaultSyncPolicyValue,
+	},
+	SyncInterval: Property{
+		Name:  constants.SyncInterval,
+		Value: constants.DefaultSyncIntervalValue,
+	},
+	SyncLogLevel: Property{
+		Name:  constants.SyncLogLevel,
+		Value: constants.DefaultSyncLogLevelValue,
+	},
+	SlowExitThreshold: Property{
+		Name:  constants.SlowExitThreshold,
+		Value: constants.DefaultSlowExitThresholdValue,
+	},
+	SlowExitLogLevel: Property{
+		Name:  constants.SlowExitLogLevel,
+		Value: constants.DefaultSlowExitLogLevelValue,
+	},
+	CallerSkip: Property{
+		Name:  constants.CallerSkip,
+		Value: constants.DefaultCallerSkipValue,
+	},
+	StderrLevel: Property{
+		Name:  constants.StderrLevel,
+		Value: constants.DefaultStderrLevelValue,
+	},
+	ConsoleColor: Property{
+		Name:  constants.ConsoleColor,
+		Value: constants.DefaultConsoleColorValue,
+	},
+	LogTemplate: Property{
+		Name:  constants.LogTemplate,
+		Value: constants.DefaultLogTemplateValue,
+	},
+	LogTimeFormat: Property{
+		Name:  constants.LogTimeFormat,
+		Value: constants.DefaultLogTimeFormatValue,
+	},
+	LogTimeZone: Property{
+		Name:  constants.LogTimeZone,
+		Value: constants.DefaultLogTimeZoneValue,
+	},
+	LogTimePrecision: Property{
+		Name:  constants.LogTimePrecision,
+		Value: constants.DefaultLogTimePrecisionValue,
+	},
+	RolloverInterval: Property{
+		Name:  constants.RolloverInterval,
+		Value: constants.DefaultRolloverIntervalValue,
+	},
+	MaxBackups: Property{
+		Name:  constants.MaxBackups,
+		Value: constants.DefaultMaxBackupsValue,
+	},
+	MaxAgeDays: Property{
+		Name:  constants.MaxAgeDays,
+		Value: constants.DefaultMaxAgeDaysValue,
+	},
+	MaxTotalSize: Property{
+		Name:  constants.MaxTotalSize,
+		Value: constants.DefaultMaxTotalSizeValue,
+	},
+	Compression: Property{
+		Name:  constants.Compression,
+		Value: constants.DefaultCompressionValue,
+	},
+	RolloverNamePattern: Property{
+		Name:  constants.RolloverNamePattern,
+		Value: constants.DefaultRolloverNamePatternValue,
+	},
+	RotateOnStartup: Property{
+		Name:  constants.RotateOnStartup,
+		Value: constants.DefaultRotateOnStartupValue,
+	},
+	FileCheckInterval: Property{
+		Name:  constants.FileCheckInterval,
+		Value: constants.DefaultFileCheckIntervalValue,
+	},
+	FileErrorPolicy: Property{
+		Name:  constants.FileErrorPolicy,
+		Value: constants.DefaultFileErrorPolicyValue,
+	},
 }
 
 // Function GetProperties loads in all properties, first from env variables and then from a property file
@@ -101,7 +233,7 @@ func GetProperties() KloggerProperties {
 	fExists := true
 
 	//First Check env variable for property file name
-	kp.PropFileName = loadFromEnvVariable(DefaultProperties.PropFileName)
+	kp.PropFileName, _ = loadFromEnvVariable(DefaultProperties.PropFileName)
 
 	fn, ok := kp.PropFileName.Value.(string)
 
@@ -127,18 +259,44 @@ func GetProperties() KloggerProperties {
 		panic("property file name is invalid")
 	}
 
-	//First attempt to load each value from the environment
-	kp.LogFileDir = loadFromEnvVariable(kp.LogFileDir)
-	kp.LogFileName = loadFromEnvVariable(kp.LogFileName)
-	kp.DoRollover = loadFromEnvVariable(kp.DoRollover)
-	kp.DoSizeRollover = loadFromEnvVariable(kp.DoSizeRollover)
-	kp.DoDateRollover = loadFromEnvVariable(kp.DoDateRollover)
-	kp.RolloverSize = loadFromEnvVariable(kp.RolloverSize)
-	kp.LogFileLevel = loadFromEnvVariable(kp.LogFileLevel)
-	kp.LogLevel = loadFromEnvVariable(kp.LogLevel)
-	kp.EnterLogLevel = loadFromEnvVariable(kp.EnterLogLevel)
-	kp.ExitLogLevel = loadFromEnvVariable(kp.ExitLogLevel)
-	kp.DoEnterExitLogs = loadFromEnvVariable(kp.DoEnterExitLogs)
+	//First attempt to load each value from the environment. Invalid values are reported and the property file or default value is used instead
+	kp.LogFileDir = reportEnvError(loadFromEnvVariable(kp.LogFileDir))
+	kp.LogFileName = reportEnvError(loadFromEnvVariable(kp.LogFileName))
+	kp.DoRollover = reportEnvError(loadFromEnvVariable(kp.DoRollover))
+	kp.DoSizeRollover = reportEnvError(loadFromEnvVariable(kp.DoSizeRollover))
+	kp.DoDateRollover = reportEnvError(loadFromEnvVariable(kp.DoDateRollover))
+	kp.RolloverSize = reportEnvError(loadFromEnvVariable(kp.RolloverSize))
+	kp.LogFileLevel = reportEnvError(loadFromEnvVariable(kp.LogFileLevel))
+	kp.LogLevel = reportEnvError(loadFromEnvVariable(kp.LogLevel))
+	kp.EnterLogLevel = reportEnvError(loadFromEnvVariable(kp.EnterLogLevel))
+	kp.ExitLogLevel = reportEnvError(loadFromEnvVariable(kp.ExitLogLevel))
+	kp.DoEnterExitLogs = reportEnvError(loadFromEnvVariable(kp.DoEnterExitLogs))
+	kp.LogFormat = reportEnvError(loadFromEnvVariable(kp.LogFormat))
+	kp.LogFileFormat = reportEnvError(loadFromEnvVariable(kp.LogFileFormat))
+	kp.DoAsync = reportEnvError(loadFromEnvVariable(kp.DoAsync))
+	kp.AsyncQueueSize = reportEnvError(loadFromEnvVariable(kp.AsyncQueueSize))
+	kp.AsyncOverflowPolicy = reportEnvError(loadFromEnvVariable(kp.AsyncOverflowPolicy))
+	kp.SyncPolicy = reportEnvError(loadFromEnvVariable(kp.SyncPolicy))
+	kp.SyncInterval = reportEnvError(loadFromEnvVariable(kp.SyncInterval))
+	kp.SyncLogLevel = reportEnvError(loadFromEnvVariable(kp.SyncLogLevel))
+	kp.SlowExitThreshold = reportEnvError(loadFromEnvVariable(kp.SlowExitThreshold))
+	kp.SlowExitLogLevel = reportEnvError(loadFromEnvVariable(kp.SlowExitLogLevel))
+	kp.CallerSkip = reportEnvError(loadFromEnvVariable(kp.CallerSkip))
+	kp.StderrLevel = reportEnvError(loadFromEnvVariable(kp.StderrLevel))
+	kp.ConsoleColor = reportEnvError(loadFromEnvVariable(kp.ConsoleColor))
+	kp.LogTemplate = reportEnvError(loadFromEnvVariable(kp.LogTemplate))
+	kp.LogTimeFormat = reportEnvError(loadFromEnvVariable(kp.LogTimeFormat))
+	kp.LogTimeZone = reportEnvError(loadFromEnvVariable(kp.LogTimeZone))
+	kp.LogTimePrecision = reportEnvError(loadFromEnvVariable(kp.LogTimePrecision))
+	kp.RolloverInterval = reportEnvError(loadFromEnvVariable(kp.RolloverInterval))
+	kp.MaxBackups = reportEnvError(loadFromEnvVariable(kp.MaxBackups))
+	kp.MaxAgeDays = reportEnvError(loadFromEnvVariable(kp.MaxAgeDays))
+	kp.MaxTotalSize = reportEnvError(loadFromEnvVariable(kp.MaxTotalSize))
+	kp.Compression = reportEnvError(loadFromEnvVariable(kp.Compression))
+	kp.RolloverNamePattern = reportEnvError(loadFromEnvVariable(kp.RolloverNamePattern))
+	kp.RotateOnStartup = reportEnvError(loadFromEnvVariable(kp.RotateOnStartup))
+	kp.FileCheckInterval = reportEnvError(loadFromEnvVariable(kp.FileCheckInterval))
+	kp.FileErrorPolicy = reportEnvError(loadFromEnvVariable(kp.FileErrorPolicy))
 
 	//Next attempt to load each value from the property file if it exists
 	if fExists {
@@ -153,26 +311,105 @@ func GetProperties() KloggerProperties {
 		kp.EnterLogLevel = loadProperty(kp.EnterLogLevel, pfd)
 		kp.ExitLogLevel = loadProperty(kp.ExitLogLevel, pfd)
 		kp.DoEnterExitLogs = loadProperty(kp.DoEnterExitLogs, pfd)
+		kp.LogFormat = loadProperty(kp.LogFormat, pfd)
+		kp.LogFileFormat = loadProperty(kp.LogFileFormat, pfd)
+		kp.DoAsync = loadProperty(kp.DoAsync, pfd)
+		kp.AsyncQueueSize = loadProperty(kp.AsyncQueueSize, pfd)
+		kp.AsyncOverflowPolicy = loadProperty(kp.AsyncOverflowPolicy, pfd)
+		kp.SyncPolicy = loadProperty(kp.SyncPolicy, pfd)
+		kp.SyncInterval = loadProperty(kp.SyncInterval, pfd)
+		kp.SyncLogLevel = loadProperty(kp.SyncLogLevel, pfd)
+		kp.SlowExitThreshold = loadProperty(kp.SlowExitThreshold, pfd)
+		kp.SlowExitLogLevel = loadProperty(kp.SlowExitLogLevel, pfd)
+		kp.CallerSkip = loadProperty(kp.CallerSkip, pfd)
+		kp.StderrLevel = loadProperty(kp.StderrLevel, pfd)
+		kp.ConsoleColor = loadProperty(kp.ConsoleColor, pfd)
+		kp.LogTemplate = loadProperty(kp.LogTemplate, pfd)
+		kp.LogTimeFormat = loadProperty(kp.LogTimeFormat, pfd)
+		kp.LogTimeZone = loadProperty(kp.LogTimeZone, pfd)
+		kp.LogTimePrecision = loadProperty(kp.LogTimePrecision, pfd)
+		kp.RolloverInterval = loadProperty(kp.RolloverInterval, pfd)
+		kp.MaxBackups = loadProperty(kp.MaxBackups, pfd)
+		kp.MaxAgeDays = loadProperty(kp.MaxAgeDays, pfd)
+		kp.MaxTotalSize = loadProperty(kp.MaxTotalSize, pfd)
+		kp.Compression = loadProperty(kp.Compression, pfd)
+		kp.RolloverNamePattern = loadProperty(kp.RolloverNamePattern, pfd)
+		kp.RotateOnStartup = loadProperty(kp.RotateOnStartup, pfd)
+		kp.FileCheckInterval = loadProperty(kp.FileCheckInterval, pfd)
+		kp.FileErrorPolicy = loadProperty(kp.FileErrorPolicy, pfd)
 	}
 
 	return kp
 }
 
 // Function loadFromEnvVariable attempts to update a single property value from an environment variable
-func loadFromEnvVariable(p Property) Property {
+// The value is parsed into the type of the property's current value. If it cannot be parsed the property is returned unchanged along with an error
+func loadFromEnvVariable(p Property) (Property, error) {
 
 	//Attempt to get the environment variable
-	v := os.Getenv(constants.EnvPrefix + p.Name)
+	n := constants.EnvPrefix + p.Name
+	v := os.Getenv(n)
 
-	if v != "" {
-		fmt.Printf("[Klogger] Read environment variable for property: %s\n", p.Name)
-		p.Value = v
-		p.isLoaded = true
+	if v == "" {
+		return p, nil
+	}
+
+	fmt.Printf("[Klogger] Read environment variable for property: %s\n", p.Name)
+
+	pv, err := parseValue(v, p.Value)
+
+	if err != nil {
+		return p, fmt.Errorf("environment variable %s is invalid: %w", n, err)
+	}
+
+	p.Value = pv
+	p.isLoaded = true
+
+	return p, nil
+}
+
+// Function reportEnvError prints an error returned when loading a property from an environment variable and returns the property
+func reportEnvError(p Property, err error) Property {
+	if err != nil {
+		fmt.Printf("[Klogger] %v\n", err)
 	}
 
 	return p
 }
 
+// Function parseValue parses a string into the same type as t
+func parseValue(s string, t interface{}) (interface{}, error) {
+	s = strings.TrimSpace(s)
+
+	switch t.(type) {
+	case bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a bool", s)
+		}
+		return b, nil
+
+	case int:
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an int", s)
+		}
+		return i, nil
+
+	case int64:
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an int64", s)
+		}
+		return i, nil
+
+	case loglevel.LogLevel:
+		return loglevel.Parse(s)
+	}
+
+	return s, nil
+}
+
 // Function loadProperty attempts to Load a single property from a property object if it has not already been loaded
 func loadProperty(p Property, pfd PropertyFileData) Property {
 
@@ -188,25 +425,38 @@ func loadProperty(p Property, pfd PropertyFileData) Property {
 
 func GetPropString(p Property) string {
 	s, ok := p.Value.(string)
-	validateValue(p.Name, ok)
+	validateValue(p, ok)
 
 	return s
 }
 
 func GetPropBool(p Property) bool {
 	b, ok := p.Value.(bool)
-	validateValue(p.Name, ok)
+	validateValue(p, ok)
 
 	return b
 }
 
 func GetPropInt(p Property) int {
 	i, ok := p.Value.(int)
-	validateValue(p.Name, ok)
+	validateValue(p, ok)
 
 	return i
 }
 
+func GetPropInt64(p Property) int64 {
+	switch i := p.Value.(type) {
+	case int64:
+		return i
+	case int:
+		return int64(i)
+	}
+
+	validateValue(p, false)
+
+	return 0
+}
+
 func GetPropLogLevel(p Property) loglevel.LogLevel {
 
 	//First check if allready typed to LogLevel
@@ -216,15 +466,37 @@ func GetPropLogLevel(p Property) loglevel.LogLevel {
 		return ll
 	}
 
+	//Integers are validated the same way as names
 	lli, ok := p.Value.(int)
 
-	validateValue(p.Name, ok)
+	if ok {
+		ll, err := loglevel.Parse(strconv.Itoa(lli))
+		validateValue(p, err == nil)
+
+		return ll
+	}
+
+	//YAML 1.1 reads an unquoted off as false
+	llb, ok := p.Value.(bool)
+
+	if ok {
+		validateValue(p, !llb)
+
+		return loglevel.None
+	}
+
+	//Finally accept level names such as info or WARN
+	lls, ok := p.Value.(string)
+	validateValue(p, ok)
+
+	ll, err := loglevel.Parse(lls)
+	validateValue(p, err == nil)
 
-	return loglevel.GetLogLevel(lli)
+	return ll
 }
 
-func validateValue(n string, isValid bool) {
+func validateValue(p Property, isValid bool) {
 	if !isValid {
-		panic(fmt.Sprintf("property for %s is invalid", n))
+		panic(fmt.Sprintf("property for %s is invalid: %v (%T)", p.Name, p.Value, p.Value))
 	}
 }
@@ -1,8 +1,8 @@
 package properties
 
 import (
-	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -15,10 +15,9 @@ func TestGetProperties(t *testing.T) {
 	d := DefaultProperties
 	//	o := "overridden value"
 
-	fn := "\\properties\\test\\klogger-loadconf-properties.yml"
+	fn := filepath.Join("properties", "test", "klogger-loadconf-properties.yml")
 	fp, err := getFilePath()
-	fp = strings.Replace(fp, "\\internal\\properties", "", -1)
-	ffn := fmt.Sprintf("%s%s", fp, fn)
+	ffn := filepath.Join(fp, fn)
 	assert.Nil(t, err)
 
 	//First Assert that the default filepath is returned if the env variable is not set
@@ -53,7 +52,8 @@ func TestLoadFromEnvVariable(t *testing.T) {
 		Value: vd,
 	}
 
-	p1 := loadFromEnvVariable(p)
+	p1, err := loadFromEnvVariable(p)
+	assert.Nil(t, err)
 
 	s, ok := p1.Value.(string)
 
@@ -61,6 +61,74 @@ func TestLoadFromEnvVariable(t *testing.T) {
 	assert.Equal(t, v, s)
 }
 
+func TestLoadFromEnvVariableTyped(t *testing.T) {
+	tests := []struct {
+		name     string
+		def      interface{}
+		env      string
+		expected interface{}
+	}{
+		{"Bool", true, "false", false},
+		{"Int", 1, " 42 ", 42},
+		{"Int64", int64(1), "104857600", int64(104857600)},
+		{"LogLevel", loglevel.Debug, "3", loglevel.Info},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(constants.EnvPrefix+tt.name, tt.env)
+
+			p, err := loadFromEnvVariable(Property{Name: tt.name, Value: tt.def})
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, p.Value)
+			assert.True(t, p.isLoaded)
+		})
+	}
+}
+
+func TestLoadFromEnvVariableInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		def  interface{}
+		env  string
+	}{
+		{"Bool", true, "maybe"},
+		{"Int", 1, "one"},
+		{"Int64", int64(1), "1.5"},
+		{"LogLevel", loglevel.Debug, "7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(constants.EnvPrefix+tt.name, tt.env)
+
+			//The property should be returned unchanged with an error naming the variable
+			p, err := loadFromEnvVariable(Property{Name: tt.name, Value: tt.def})
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), constants.EnvPrefix+tt.name)
+			assert.Equal(t, tt.def, p.Value)
+			assert.False(t, p.isLoaded)
+		})
+	}
+}
+
+func TestGetPropertiesEnvTypes(t *testing.T) {
+	t.Setenv(constants.EnvPrefix+constants.DoRollover, "false")
+	t.Setenv(constants.EnvPrefix+constants.LogLevel, "3")
+	t.Setenv(constants.EnvPrefix+constants.RolloverSize, "2048")
+	t.Setenv(constants.EnvPrefix+constants.DoSizeRollover, "invalid")
+
+	kp := GetProperties()
+
+	//Env overrides should be usable by the typed getters without panicking
+	assert.False(t, GetPropBool(kp.DoRollover))
+	assert.Equal(t, loglevel.Info, GetPropLogLevel(kp.LogLevel))
+	assert.Equal(t, int64(2048), GetPropInt64(kp.RolloverSize))
+
+	//Invalid values fall back to the default
+	assert.Equal(t, constants.DefaultDoSizeRolloverValue, GetPropBool(kp.DoSizeRollover))
+}
+
 func TestLoadProperty(t *testing.T) {
 
 	p := Property{
@@ -148,6 +216,46 @@ func TestGetPropInt(t *testing.T) {
 
 }
 
+func TestGetPropertiesLogLevelNames(t *testing.T) {
+	fp, err := getFilePath()
+	assert.Nil(t, err)
+
+	t.Setenv("KloggerPropFileName", filepath.Join(fp, "properties", "test", "klogger-loglevel-names-properties.yml"))
+	t.Setenv(constants.EnvPrefix+constants.SyncLogLevel, "err")
+
+	kp := GetProperties()
+
+	assert.Equal(t, loglevel.Warn, GetPropLogLevel(kp.LogLevel))
+	assert.Equal(t, loglevel.Debug, GetPropLogLevel(kp.LogFileLevel))
+	assert.Equal(t, loglevel.Trace, GetPropLogLevel(kp.EnterLogLevel))
+	assert.Equal(t, loglevel.Warn, GetPropLogLevel(kp.ExitLogLevel))
+	assert.Equal(t, loglevel.Error, GetPropLogLevel(kp.SyncLogLevel))
+	assert.Equal(t, loglevel.None, GetPropLogLevel(kp.SlowExitLogLevel))
+
+	//The sample property file should also load
+	t.Setenv("KloggerPropFileName", filepath.Join(fp, "properties", "klogger-properties.yml"))
+	kp = GetProperties()
+
+	assert.Equal(t, loglevel.Info, GetPropLogLevel(kp.LogLevel))
+	assert.Equal(t, loglevel.Debug, GetPropLogLevel(kp.LogFileLevel))
+}
+
+func TestGetPropInt64(t *testing.T) {
+	p := Property{
+		Name:  "prop",
+		Value: int64(1),
+	}
+
+	assert.Equal(t, int64(1), GetPropInt64(p))
+
+	//Also accepts ints as read from property files
+	p.Value = 2
+	assert.Equal(t, int64(2), GetPropInt64(p))
+
+	p.Value = "str"
+	assert.Panics(t, func() { GetPropInt64(p) })
+}
+
 func TestGetPropLogLevel(t *testing.T) {
 	v := loglevel.Debug
 	p := Property{
@@ -165,9 +273,27 @@ func TestGetPropLogLevel(t *testing.T) {
 	s = GetPropLogLevel(p)
 	assert.Equal(t, v, s)
 
+	//Also accepts level names
+	p.Value = "Warning"
+	s = GetPropLogLevel(p)
+	assert.Equal(t, loglevel.Warn, s)
+
 	p.Value = "str"
 	assert.Panics(t, func() { GetPropLogLevel(p) })
 
+	//Integers outside the range of log levels are invalid
+	p.Value = 7
+	assert.Panics(t, func() { GetPropLogLevel(p) })
+
+	p.Value = 4
+	assert.Equal(t, loglevel.Warn, GetPropLogLevel(p))
+
+	//An unquoted off in a property file is read as false
+	p.Value = false
+	assert.Equal(t, loglevel.None, GetPropLogLevel(p))
+
+	p.Value = true
+	assert.Panics(t, func() { GetPropLogLevel(p) })
 }
 
 func getFilePath() (string, error) {
@@ -178,8 +304,8 @@ func getFilePath() (string, error) {
 	}
 
 	//Do this to handle both windows and linux file systems
-	fp = strings.Replace(fp, "internal\\config", "", -1)
-	fp = strings.Replace(fp, "internal/config", "", -1)
+	fp = strings.Replace(fp, "internal\\properties", "", -1)
+	fp = strings.Replace(fp, "internal/properties", "", -1)
 
 	return fp, nil
 }
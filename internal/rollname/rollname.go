@@ -0,0 +1,210 @@
+// Package rollname builds and matches the names given to rolled over log files
+package rollname
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Tokens which can be used in a naming pattern, written between braces
+const (
+	Base = "base" //The log file name without its extension
+	Date = "date" //The label of the rollover interval, such as 2024-02-15
+	N    = "n"    //The number of the rolled over file within the interval, starting at 1
+	Ext  = "ext"  //The extension of the log file including its dot, or nothing if it has none
+)
+
+// The pattern used when none is configured
+const Default = "{base}_{date}_{n}{ext}"
+
+// Type segment is a part of a pattern. Either token or text is set
+type segment struct {
+	token string
+	text  string
+}
+
+// Type Pattern is a parsed naming pattern. The zero value uses the Default pattern
+type Pattern struct {
+	segments []segment
+}
+
+// Function Parse parses a naming pattern such as "{base}-{date}.{n}{ext}"
+// An empty pattern returns the zero Pattern, which uses the Default pattern
+// Returns an error if a token is unknown, a brace is not closed, {base}, {date} or {n} do not appear exactly once, or {n} is not separated from {date} by text
+func Parse(s string) (Pattern, error) {
+	if s == "" {
+		return Pattern{}, nil
+	}
+
+	var p Pattern
+	counts := map[string]int{}
+
+	for rest := s; rest != ""; {
+		i := strings.IndexByte(rest, '{')
+
+		if i < 0 {
+			p.segments = append(p.segments, segment{text: rest})
+			break
+		}
+
+		if i > 0 {
+			p.segments = append(p.segments, segment{text: rest[:i]})
+		}
+
+		j := strings.IndexByte(rest[i:], '}')
+
+		if j < 0 {
+			return Pattern{}, fmt.Errorf("unclosed '{' in naming pattern %q", s)
+		}
+
+		name := rest[i+1 : i+j]
+
+		switch name {
+		case Base, Date, N, Ext:
+		default:
+			return Pattern{}, fmt.Errorf("unknown token {%s} in naming pattern %q", name, s)
+		}
+
+		counts[name]++
+		p.segments = append(p.segments, segment{token: name})
+		rest = rest[i+j+1:]
+	}
+
+	if counts[Date] != 1 || counts[N] != 1 {
+		return Pattern{}, fmt.Errorf("naming pattern %q must contain {date} and {n} exactly once", s)
+	}
+
+	//Without the base, loggers sharing a directory would roll over to the same names and prune each other's files
+	if counts[Base] != 1 {
+		return Pattern{}, fmt.Errorf("naming pattern %q must contain {base} exactly once", s)
+	}
+
+	//Without text between them the end of the date could not be told apart from the start of the number
+	for i := 1; i < len(p.segments); i++ {
+		a, b := p.segments[i-1].token, p.segments[i].token
+
+		if (a == Date && b == N) || (a == N && b == Date) {
+			return Pattern{}, fmt.Errorf("naming pattern %q must separate {date} and {n}", s)
+		}
+	}
+
+	return p, nil
+}
+
+// var defaultPattern is the parsed Default pattern
+var defaultPattern, _ = Parse(Default)
+
+// Function orDefault returns the segments of p, or of the Default pattern for the zero value
+func (p Pattern) orDefault() []segment {
+	if len(p.segments) == 0 {
+		return defaultPattern.segments
+	}
+
+	return p.segments
+}
+
+// Function SplitName splits a log file name into its base and extension. The extension starts at the last dot, so my.app.log has the base my.app and the extension .log
+func SplitName(fn string) (string, string) {
+	ext := filepath.Ext(fn)
+
+	//A leading dot, as in .log, starts the name rather than an extension
+	if ext == fn {
+		ext = ""
+	}
+
+	return strings.TrimSuffix(fn, ext), ext
+}
+
+// Function Name returns the rolled over file name for a log file
+// fn - the name of the log file, without its directory
+// date - the label of the rollover interval
+// n - the number of the rolled over file
+func (p Pattern) Name(fn string, date string, n int) string {
+	base, ext := SplitName(fn)
+
+	var sb strings.Builder
+
+	for _, sg := range p.orDefault() {
+		switch sg.token {
+		case Base:
+			sb.WriteString(base)
+		case Date:
+			sb.WriteString(date)
+		case N:
+			sb.WriteString(strconv.Itoa(n))
+		case Ext:
+			sb.WriteString(ext)
+		default:
+			sb.WriteString(sg.text)
+		}
+	}
+
+	return sb.String()
+}
+
+// The interval labels {date} can hold: a day such as 2024-02-15 with optional -HH, -MM and -SS parts after a T, a month such as 2024-02, or a week such as 2024-W07
+const dateExpr = `[0-9]{4}-(?:[0-9]{2}(?:-[0-9]{2}(?:T[0-9]{2}(?:-[0-9]{2}(?:-[0-9]{2})?)?)?)?|W[0-9]{2})`
+
+// Type Matcher matches the names of rolled over files for a single log file
+type Matcher struct {
+	re *regexp.Regexp
+}
+
+// Function Matcher returns a Matcher for the rolled over files of a log file
+// fn - the name of the log file, without its directory
+// suffixes - extensions which may follow a rolled over file name, such as those added by compression
+func (p Pattern) Matcher(fn string, suffixes ...string) Matcher {
+	base, ext := SplitName(fn)
+
+	var sb strings.Builder
+	sb.WriteString("^")
+
+	for _, sg := range p.orDefault() {
+		switch sg.token {
+		case Base:
+			sb.WriteString(regexp.QuoteMeta(base))
+		case Date:
+			sb.WriteString(`(?P<date>` + dateExpr + `)`)
+		case N:
+			sb.WriteString(`(?P<n>[0-9]+)`)
+		case Ext:
+			sb.WriteString(regexp.QuoteMeta(ext))
+		default:
+			sb.WriteString(regexp.QuoteMeta(sg.text))
+		}
+	}
+
+	if len(suffixes) > 0 {
+		qs := make([]string, len(suffixes))
+
+		for i, s := range suffixes {
+			qs[i] = regexp.QuoteMeta(s)
+		}
+
+		sb.WriteString("(?:" + strings.Join(qs, "|") + ")?")
+	}
+
+	sb.WriteString("$")
+
+	return Matcher{re: regexp.MustCompile(sb.String())}
+}
+
+// Function Match returns the interval label and number of a rolled over file name, or false if the name is not one
+func (m Matcher) Match(name string) (string, int, bool) {
+	sm := m.re.FindStringSubmatch(name)
+
+	if sm == nil {
+		return "", 0, false
+	}
+
+	n, err := strconv.Atoi(sm[m.re.SubexpIndex(N)])
+
+	if err != nil {
+		return "", 0, false
+	}
+
+	return sm[m.re.SubexpIndex(Date)], n, true
+}
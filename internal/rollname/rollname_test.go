@@ -0,0 +1,144 @@
+package rollname
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		pattern string
+		wantErr bool
+	}{
+		{pattern: Default},
+		{pattern: "{base}-{date}.{n}{ext}"},
+		{pattern: "archive-{base}-{date}-{n}"},
+		{pattern: "{date}_{n}{ext}", wantErr: true},
+		{pattern: "{base}_{base}_{date}_{n}{ext}", wantErr: true},
+		{pattern: "{base}_{date}{ext}", wantErr: true},
+		{pattern: "{base}_{n}{ext}", wantErr: true},
+		{pattern: "{base}_{date}_{n}_{n}{ext}", wantErr: true},
+		{pattern: "{base}_{date}{n}{ext}", wantErr: true},
+		{pattern: "{base}_{day}_{n}{ext}", wantErr: true},
+		{pattern: "{base}_{date}_{n", wantErr: true},
+		{pattern: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			_, err := Parse(tt.pattern)
+			assert.Equal(t, tt.wantErr, err != nil, err)
+		})
+	}
+}
+
+func TestSplitName(t *testing.T) {
+	tests := []struct {
+		fn   string
+		base string
+		ext  string
+	}{
+		{"application.log", "application", ".log"},
+		{"my_app.log", "my_app", ".log"},
+		{"my.app.log", "my.app", ".log"},
+		{"application", "application", ""},
+		{".log", ".log", ""},
+	}
+
+	for _, tt := range tests {
+		base, ext := SplitName(tt.fn)
+		assert.Equal(t, tt.base, base, tt.fn)
+		assert.Equal(t, tt.ext, ext, tt.fn)
+	}
+}
+
+func TestNameAndMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		fn      string
+		date    string
+		n       int
+		want    string
+	}{
+		{Default, "application.log", "2024-02-15", 3, "application_2024-02-15_3.log"},
+		{Default, "my_app.log", "2024-02-15", 1, "my_app_2024-02-15_1.log"},
+		{Default, "my.app.log", "2024-02-15T10", 12, "my.app_2024-02-15T10_12.log"},
+		{Default, "application", "2024-W07", 2, "application_2024-W07_2"},
+		{"{base}-{date}.{n}{ext}", "my_app.v2.log", "2024-02-15", 4, "my_app.v2-2024-02-15.4.log"},
+		{"{date}-{n}-{base}{ext}", "app.log", "2024-02", 1, "2024-02-1-app.log"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			p, err := Parse(tt.pattern)
+			assert.Nil(t, err)
+
+			name := p.Name(tt.fn, tt.date, tt.n)
+			assert.Equal(t, tt.want, name)
+
+			//Names are matched with and without a compression suffix
+			m := p.Matcher(tt.fn, ".gz")
+
+			for _, n := range []string{name, name + ".gz"} {
+				date, num, ok := m.Match(n)
+				assert.True(t, ok, n)
+				assert.Equal(t, tt.date, date, n)
+				assert.Equal(t, tt.n, num, n)
+			}
+
+			//The log file itself and other files are not matched
+			for _, n := range []string{tt.fn, name + ".bak", "_" + name} {
+				_, _, ok := m.Match(n)
+				assert.False(t, ok, n)
+			}
+		})
+	}
+}
+
+func TestMatchOtherFiles(t *testing.T) {
+	tests := []struct {
+		pattern string
+		fn      string
+		name    string
+	}{
+		//Rolled over files of another log file sharing the base as a prefix
+		{"{base}-{date}.{n}{ext}", "app.log", "app-audit-2024-02-15.1.log"},
+		{Default, "application.log", "application_audit_2024-02-15_1.log"},
+		//Names with something other than an interval label in place of the date
+		{Default, "application.log", "application_backup_1.log"},
+		{Default, "application.log", "application_2024_1.log"},
+		{Default, "application.log", "application_2024-02-15T10-37-42-01_1.log"},
+		{Default, "application.log", "application_2024-W7_1.log"},
+		{Default, "application.log", "application_24-02-15_1.log"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Parse(tt.pattern)
+			assert.Nil(t, err)
+
+			_, _, ok := p.Matcher(tt.fn, ".gz").Match(tt.name)
+			assert.False(t, ok)
+		})
+	}
+
+	//Every label shape rollover intervals produce is matched
+	m := Pattern{}.Matcher("application.log")
+
+	for _, date := range []string{"2024-02-15", "2024-02-15T10", "2024-02-15T10-37", "2024-02-15T10-37-42", "2024-02", "2024-W07"} {
+		got, n, ok := m.Match("application_" + date + "_3.log")
+		assert.True(t, ok, date)
+		assert.Equal(t, date, got)
+		assert.Equal(t, 3, n)
+	}
+}
+
+func TestZeroPattern(t *testing.T) {
+	var p Pattern
+
+	assert.Equal(t, "application_2024-02-15_1.log", p.Name("application.log", "2024-02-15", 1))
+
+	_, _, ok := p.Matcher("application.log").Match("application_2024-02-15_1.log")
+	assert.True(t, ok)
+}
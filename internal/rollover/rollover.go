@@ -0,0 +1,143 @@
+// Package rollover splits time into the intervals used for time based log file rollover
+package rollover
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jon-kamis/klogger/internal/utils"
+)
+
+// Interval units. Names are compared case-insensitively
+const (
+	Minute = "minute"
+	Hour   = "hour"
+	Day    = "day"
+	Week   = "week" //Weeks start on Monday
+	Month  = "month"
+	every  = "every" //A fixed duration given with @every
+)
+
+// Cron-like descriptors accepted in place of an interval unit
+var descriptors = map[string]string{
+	"@hourly":   Hour,
+	"@daily":    Day,
+	"@midnight": Day,
+	"@weekly":   Week,
+	"@monthly":  Month,
+}
+
+// Type Interval is a rollover interval. The zero value rolls over daily
+type Interval struct {
+	unit  string
+	every time.Duration //Only set for @every intervals
+}
+
+// Function Parse parses a rollover interval. Accepts minute, hour, day, week and month, the cron-like descriptors @hourly, @daily, @midnight, @weekly and @monthly, or @every followed by a duration such as "@every 15m". An empty interval returns the zero Interval, which rolls over daily
+func Parse(s string) (Interval, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	switch s {
+	case "":
+		return Interval{}, nil
+	case Minute, Hour, Day, Week, Month:
+		return Interval{unit: s}, nil
+	}
+
+	if u, ok := descriptors[s]; ok {
+		return Interval{unit: u}, nil
+	}
+
+	if d, ok := strings.CutPrefix(s, "@every "); ok {
+		dur, err := time.ParseDuration(strings.TrimSpace(d))
+
+		if err != nil || dur < time.Second {
+			return Interval{}, fmt.Errorf("invalid rollover interval %q: @every needs a duration of at least 1s", s)
+		}
+
+		return Interval{unit: every, every: dur}, nil
+	}
+
+	return Interval{}, fmt.Errorf("invalid rollover interval %q", s)
+}
+
+// Function Start returns the start of the interval containing t, in the location of t
+func (i Interval) Start(t time.Time) time.Time {
+	switch i.unit {
+	case Minute:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, t.Location())
+	case Hour:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	case Week:
+		//Go weeks start on Sunday so shift them to start on Monday
+		return utils.GetStartOfDay(t).AddDate(0, 0, -(int(t.Weekday())+6)%7)
+	case Month:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	case every:
+		//Durations of up to a day are counted from midnight so that their boundaries fall at the same times each day
+		if i.every <= 24*time.Hour {
+			sod := utils.GetStartOfDay(t)
+			return sod.Add(t.Sub(sod) / i.every * i.every)
+		}
+
+		return t.Truncate(i.every)
+	}
+
+	return utils.GetStartOfDay(t)
+}
+
+// Function Next returns the start of the interval following the one starting at start
+func (i Interval) Next(start time.Time) time.Time {
+	switch i.unit {
+	case Minute:
+		return start.Add(time.Minute)
+	case Hour:
+		return start.Add(time.Hour)
+	case Week:
+		return start.AddDate(0, 0, 7)
+	case Month:
+		return start.AddDate(0, 1, 0)
+	case every:
+		next := start.Add(i.every)
+
+		//Intervals counted from midnight restart at the next midnight
+		if i.every <= 24*time.Hour {
+			if sod := utils.GetStartOfDay(start).AddDate(0, 0, 1); next.After(sod) {
+				return sod
+			}
+		}
+
+		return next
+	}
+
+	return start.AddDate(0, 0, 1)
+}
+
+// Function Label returns the text written in the names of files rolled over during the interval starting at start
+func (i Interval) Label(start time.Time) string {
+	switch i.unit {
+	case Minute:
+		return start.Format("2006-01-02T15-04")
+	case Hour:
+		return start.Format("2006-01-02T15")
+	case Week:
+		y, w := start.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", y, w)
+	case Month:
+		return start.Format("2006-01")
+	case every:
+		switch {
+		case i.every%(24*time.Hour) == 0:
+			return start.Format("2006-01-02")
+		case i.every%time.Hour == 0:
+			return start.Format("2006-01-02T15")
+		case i.every%time.Minute == 0:
+			return start.Format("2006-01-02T15-04")
+		}
+
+		return start.Format("2006-01-02T15-04-05")
+	}
+
+	return start.Format("2006-01-02")
+}
@@ -0,0 +1,81 @@
+package rollover
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	valid := map[string]Interval{
+		"minute":       {unit: Minute},
+		"Hour":         {unit: Hour},
+		" day ":        {unit: Day},
+		"week":         {unit: Week},
+		"MONTH":        {unit: Month},
+		"@hourly":      {unit: Hour},
+		"@daily":       {unit: Day},
+		"@midnight":    {unit: Day},
+		"@weekly":      {unit: Week},
+		"@monthly":     {unit: Month},
+		"@every 15m":   {unit: every, every: 15 * time.Minute},
+		"@every 1h30m": {unit: every, every: 90 * time.Minute},
+		"":             {},
+	}
+
+	for s, want := range valid {
+		got, err := Parse(s)
+		assert.Nil(t, err, s)
+		assert.Equal(t, want, got, s)
+	}
+
+	for _, s := range []string{"yearly", "@every", "@every 10ms", "@every fortnight", "0 * * * *"} {
+		_, err := Parse(s)
+		assert.NotNil(t, err, s)
+	}
+}
+
+func TestInterval(t *testing.T) {
+	//Thursday
+	ts := time.Date(2024, 2, 15, 10, 37, 42, 0, time.UTC)
+
+	tests := []struct {
+		spec  string
+		start time.Time
+		next  time.Time
+		label string
+	}{
+		{"minute", time.Date(2024, 2, 15, 10, 37, 0, 0, time.UTC), time.Date(2024, 2, 15, 10, 38, 0, 0, time.UTC), "2024-02-15T10-37"},
+		{"hour", time.Date(2024, 2, 15, 10, 0, 0, 0, time.UTC), time.Date(2024, 2, 15, 11, 0, 0, 0, time.UTC), "2024-02-15T10"},
+		{"day", time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 16, 0, 0, 0, 0, time.UTC), "2024-02-15"},
+		{"week", time.Date(2024, 2, 12, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 19, 0, 0, 0, 0, time.UTC), "2024-W07"},
+		{"month", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), "2024-02"},
+		{"@every 15m", time.Date(2024, 2, 15, 10, 30, 0, 0, time.UTC), time.Date(2024, 2, 15, 10, 45, 0, 0, time.UTC), "2024-02-15T10-30"},
+		{"@every 6h", time.Date(2024, 2, 15, 6, 0, 0, 0, time.UTC), time.Date(2024, 2, 15, 12, 0, 0, 0, time.UTC), "2024-02-15T06"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			i, err := Parse(tt.spec)
+			assert.Nil(t, err)
+
+			assert.Equal(t, tt.start, i.Start(ts))
+			assert.Equal(t, tt.next, i.Next(tt.start))
+			assert.Equal(t, tt.label, i.Label(tt.start))
+		})
+	}
+
+	//The zero value rolls over daily
+	assert.Equal(t, time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC), Interval{}.Start(ts))
+
+	//Weeks start on Monday, including for Sundays
+	sunday := time.Date(2024, 2, 18, 23, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2024, 2, 12, 0, 0, 0, 0, time.UTC), Interval{unit: Week}.Start(sunday))
+
+	//Durations which do not divide a day restart at midnight
+	i, _ := Parse("@every 7h")
+	last := i.Start(time.Date(2024, 2, 15, 23, 0, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2024, 2, 15, 21, 0, 0, 0, time.UTC), last)
+	assert.Equal(t, time.Date(2024, 2, 16, 0, 0, 0, 0, time.UTC), i.Next(last))
+}
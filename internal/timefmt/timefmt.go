@@ -0,0 +1,156 @@
+// Package timefmt formats the timestamps written with logs and converts times into the configured time zone
+package timefmt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jon-kamis/klogger/internal/constants"
+)
+
+// Named layouts which can be used in place of a Go time layout. Names are compared case-insensitively
+const (
+	RFC3339     = "rfc3339"
+	RFC3339Nano = "rfc3339nano"
+	Unix        = "unix"      //Seconds since the Unix epoch
+	UnixMilli   = "unixmilli" //Milliseconds since the Unix epoch
+)
+
+// Time zone names for the local time zone and UTC. Any other name is loaded from the IANA time zone database
+const (
+	Local = "Local"
+	UTC   = "UTC"
+)
+
+// The most fractional second digits which can be written
+const maxPrecision = 9
+
+// Type Format formats timestamps using a layout, time zone and precision. The zero value formats local times using constants.TimeFormat
+type Format struct {
+	layout    string         //A Go time layout, or Unix or UnixMilli
+	loc       *time.Location //nil for the local time zone
+	precision int            //Fractional second digits to keep, 0 keeps the layout as it is
+}
+
+// Function New returns a Format for the given settings
+// layout - a Go time layout or one of the named layouts. Empty uses constants.TimeFormat
+// zone - Local, UTC or an IANA time zone name such as America/New_York. Empty uses the local time zone
+// precision - the number of fractional second digits to write, between 0 and 9. Digits are added to layouts which do not already include them
+// Returns an error if the time zone cannot be loaded or the precision is out of range
+func New(layout string, zone string, precision int) (Format, error) {
+	var f Format
+
+	if precision < 0 || precision > maxPrecision {
+		return f, fmt.Errorf("time precision %d must be between 0 and %d", precision, maxPrecision)
+	}
+
+	loc, err := loadLocation(zone)
+
+	if err != nil {
+		return f, err
+	}
+
+	f.loc = loc
+	f.precision = precision
+	f.layout = expandLayout(layout, precision)
+
+	return f, nil
+}
+
+// Function loadLocation returns the location for a time zone name, or nil for the local time zone
+func loadLocation(zone string) (*time.Location, error) {
+	switch {
+	case zone == "" || strings.EqualFold(zone, Local):
+		return nil, nil
+	case strings.EqualFold(zone, UTC):
+		return time.UTC, nil
+	}
+
+	loc, err := time.LoadLocation(zone)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to load time zone %q: %v", zone, err)
+	}
+
+	return loc, nil
+}
+
+// Function expandLayout resolves named layouts and adds fractional second digits to layouts written to the second
+func expandLayout(layout string, precision int) string {
+	switch {
+	case layout == "":
+		layout = constants.TimeFormat
+	case strings.EqualFold(layout, RFC3339):
+		layout = time.RFC3339
+	case strings.EqualFold(layout, RFC3339Nano):
+		layout = time.RFC3339Nano
+	case strings.EqualFold(layout, Unix):
+		return Unix
+	case strings.EqualFold(layout, UnixMilli):
+		return UnixMilli
+	}
+
+	if precision == 0 {
+		return layout
+	}
+
+	i := strings.Index(layout, "05")
+
+	//Leave layouts without seconds or with their own fractional seconds as they are
+	if i < 0 || strings.HasPrefix(layout[i+2:], ".0") || strings.HasPrefix(layout[i+2:], ".9") || strings.HasPrefix(layout[i+2:], ",0") || strings.HasPrefix(layout[i+2:], ",9") {
+		return layout
+	}
+
+	return layout[:i+2] + "." + strings.Repeat("0", precision) + layout[i+2:]
+}
+
+// Function In returns t in the configured time zone
+func (f Format) In(t time.Time) time.Time {
+	if f.loc == nil {
+		return t.Local()
+	}
+
+	return t.In(f.loc)
+}
+
+// Function Format formats a timestamp, truncated to the configured precision, in the configured time zone
+func (f Format) Format(t time.Time) string {
+	if f.precision > 0 {
+		t = t.Truncate(time.Duration(pow10(maxPrecision - f.precision)))
+	}
+
+	switch f.layout {
+	case "":
+		return f.In(t).Format(constants.TimeFormat)
+	case Unix:
+		s := strconv.FormatInt(t.Unix(), 10)
+
+		if f.precision > 0 {
+			s += fmt.Sprintf(".%0*d", f.precision, t.Nanosecond()/pow10(maxPrecision-f.precision))
+		}
+
+		return s
+	case UnixMilli:
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	}
+
+	return f.In(t).Format(f.layout)
+}
+
+// Function IsNumeric returns true if timestamps are written as numbers rather than text
+func (f Format) IsNumeric() bool {
+	return f.layout == Unix || f.layout == UnixMilli
+}
+
+// Function pow10 returns 10 to the power of n
+func pow10(n int) int {
+	p := 1
+
+	for i := 0; i < n; i++ {
+		p *= 10
+	}
+
+	return p
+}
@@ -0,0 +1,70 @@
+package timefmt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone database is not available")
+	}
+
+	ts := time.Date(2024, 2, 15, 10, 30, 0, 123456789, time.UTC)
+
+	tests := []struct {
+		name      string
+		layout    string
+		zone      string
+		precision int
+		want      string
+	}{
+		{name: "default layout", zone: UTC, want: "2024-02-15 10:30:00"},
+		{name: "default layout with precision", zone: UTC, precision: 3, want: "2024-02-15 10:30:00.123"},
+		{name: "rfc3339", layout: "RFC3339", zone: UTC, want: "2024-02-15T10:30:00Z"},
+		{name: "rfc3339 with precision", layout: RFC3339, zone: UTC, precision: 6, want: "2024-02-15T10:30:00.123456Z"},
+		{name: "rfc3339nano", layout: RFC3339Nano, zone: UTC, want: "2024-02-15T10:30:00.123456789Z"},
+		{name: "rfc3339nano truncated", layout: RFC3339Nano, zone: UTC, precision: 2, want: "2024-02-15T10:30:00.12Z"},
+		{name: "named zone", layout: time.RFC3339, zone: "America/New_York", want: ts.In(ny).Format(time.RFC3339)},
+		{name: "unix", layout: Unix, want: "1707993000"},
+		{name: "unix with precision", layout: Unix, precision: 3, want: "1707993000.123"},
+		{name: "unix millis", layout: "UnixMilli", want: "1707993000123"},
+		{name: "unix millis truncated", layout: UnixMilli, precision: 1, want: "1707993000100"},
+		{name: "layout without seconds", layout: "15:04", zone: UTC, precision: 3, want: "10:30"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := New(tt.layout, tt.zone, tt.precision)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, f.Format(ts))
+		})
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	_, err := New("", "Not/AZone", 0)
+	assert.NotNil(t, err)
+
+	_, err = New("", UTC, 10)
+	assert.NotNil(t, err)
+
+	_, err = New("", UTC, -1)
+	assert.NotNil(t, err)
+}
+
+func TestIn(t *testing.T) {
+	ts := time.Date(2024, 2, 15, 23, 30, 0, 0, time.FixedZone("UTC-5", -5*60*60))
+
+	f, err := New("", UTC, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 16, f.In(ts).Day())
+
+	//The zero value uses the local time zone
+	assert.Equal(t, time.Local, Format{}.In(ts).Location())
+	assert.False(t, Format{}.IsNumeric())
+}
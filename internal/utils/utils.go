@@ -1,8 +1,36 @@
 package utils
 
-import "time"
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
 
 //Function GetStartOfDay returns the first millisecond of the given date
 func GetStartOfDay(t time.Time) time.Time {
 	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
 }
+
+// Function ShortFuncName returns a fully qualified function name without its package path. e.g. github.com/user/app/pkg.(*T).Method becomes pkg.(*T).Method
+func ShortFuncName(fn string) string {
+	return fn[strings.LastIndexByte(fn, '/')+1:]
+}
+
+// Function GoroutineID returns the id of the calling goroutine, read from the header of its stack trace
+func GoroutineID() uint64 {
+	var buf [64]byte
+
+	//The stack trace begins with "goroutine <id> [<state>]:"
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+
+	return id
+}
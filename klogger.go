@@ -2,124 +2,126 @@
 package klogger
 
 import (
-	"fmt"
-	"strings"
 	"time"
 
 	"github.com/jon-kamis/klogger/internal/config"
-	"github.com/jon-kamis/klogger/internal/constants"
 	"github.com/jon-kamis/klogger/internal/filelogger"
 	"github.com/jon-kamis/klogger/pkg/loglevel"
 )
 
+// var std is the default Logger used by the package level functions. It reads its settings from the property file and environment
+var std = newLogger(config.GetConfig, filelogger.Default())
+
+// Function Default returns the Logger used by the package level functions
+func Default() *Logger {
+	return std
+}
+
 // Function Enter returns a formated string used to declare where a method begins execution
 // method - The method to write an enter log for
 // l - The log levels to write to. If this is not set than the default log level for Enter logs is used
 // returns the time in which the log is written to track exit times if desired
 func Enter(method string, l ...loglevel.LogLevel) time.Time {
-
-	if !config.GetConfig().DoEnterExitLogs {
-		return time.Now()
-	}
-
-	if len(l) > 0 {
-		for _, ll := range l {
-			writeLog(constants.StdMsg, method, constants.Enter, ll)
-		}
-	} else {
-		writeLog(constants.StdMsg, method, constants.Enter, loglevel.Info)
-	}
-
-	return time.Now()
+	return std.enter(1, method, l)
 }
 
 // Function Exit returns a formated string used to declare where a method ends execution
-// method - The method to write an enter log for
-// l - The log levels to write to. If this is not set than the default log level for Enter logs is used
+// method - The method to write an exit log for
+// l - The log levels to write to. If this is not set than the default log level for Exit logs is used
 func Exit(method string, l ...loglevel.LogLevel) {
+	std.exit(1, method, l)
+}
 
-	if !config.GetConfig().DoEnterExitLogs {
-		return
-	}
-
-	if len(l) > 0 {
-		for _, ll := range l {
-			writeLog(constants.StdMsg, method, constants.Exit, ll)
-		}
-	} else {
-		writeLog(constants.StdMsg, method, constants.Exit, loglevel.Info)
-	}
+// Function ExitSince writes an exit log along with the time elapsed since start. Can be deferred with the time returned by Enter
+// method - The method to write an exit log for
+// start - The time the method began
+// l - The log levels to write to. If this is not set than the default log level for Exit logs is used
+func ExitSince(method string, start time.Time, l ...loglevel.LogLevel) {
+	std.exitSince(1, method, start, l)
 }
 
 // Function Error returns a formated string used to log a given error along with a custom error message and declaring which method the error occured in
 func Error(method string, m string, args ...any) {
-	writeLog(constants.StdMsg, method, m, loglevel.Error, args...)
+	std.writeLogf(1, method, m, loglevel.Error, args...)
 }
 
 // Function Warn returns a formated string used to log a given error along with a custom error message and declaring which method the warning occured in
 func Warn(method string, m string, args ...any) {
-	writeLog(constants.StdMsg, method, m, loglevel.Warn, args...)
+	std.writeLogf(1, method, m, loglevel.Warn, args...)
 }
 
 // Function ExitError returns a formated string used to combine the Exit and Error functions together
 func ExitError(method string, msg string, args ...any) {
-	Error(method, msg, args...)
-	Exit(method)
+	std.writeLogf(1, method, msg, loglevel.Error, args...)
+	std.exit(1, method, nil)
 }
 
 // Fucntion Info returns a formatted string containing a custom message and the method that the message is coming from
 func Info(method string, m string, args ...any) {
-	writeLog(constants.StdMsg, method, m, loglevel.Info, args...)
+	std.writeLogf(1, method, m, loglevel.Info, args...)
 }
 
 // Fucntion Debug returns a formatted string containing a custom message and the method that the message is coming from
 func Debug(method string, m string, args ...any) {
-	writeLog(constants.StdMsg, method, m, loglevel.Debug, args...)
+	std.writeLogf(1, method, m, loglevel.Debug, args...)
 }
 
 // Function Trace returns a formatted string containing a custom message and the method that the message is coming from
 func Trace(method string, m string, args ...any) {
-	writeLog(constants.StdMsg, method, m, loglevel.Trace, args...)
+	std.writeLogf(1, method, m, loglevel.Trace, args...)
 }
 
-// Function RefreshConfig causes the Klogger module to refresh its config
-func RefreshConfig() {
-	config.RefreshConfig()
+// Function With returns a copy of the default Logger which attaches the given key/value pairs to every log it writes
+func With(kv ...any) *Logger {
+	return std.With(kv...)
 }
 
-// Function writeLog writes a log to stdout and a log file
-// mt - message template
-// m - method
-// msg - message to log
-func writeLog(mt string, me string, msg string, logl loglevel.LogLevel, args ...any) {
+// Function ErrorWith writes an Error log along with the given key/value fields. The message is written as is and not used as a format template
+func ErrorWith(method string, m string, kv ...any) {
+	std.writeLog(1, method, m, loglevel.Error, appendFields(nil, kv))
+}
 
-	c := config.GetConfig()
+// Function WarnWith writes a Warn log along with the given key/value fields. The message is written as is and not used as a format template
+func WarnWith(method string, m string, kv ...any) {
+	std.writeLog(1, method, m, loglevel.Warn, appendFields(nil, kv))
+}
 
-	//Check if anything will be logged by this command
-	if logl < c.LogLevel && logl < c.LogFileLevel {
-		return
-	}
+// Function InfoWith writes an Info log along with the given key/value fields. The message is written as is and not used as a format template
+func InfoWith(method string, m string, kv ...any) {
+	std.writeLog(1, method, m, loglevel.Info, appendFields(nil, kv))
+}
 
-	//First fill in parameters
-	lmsg := fmt.Sprintf(msg, args...)
+// Function DebugWith writes a Debug log along with the given key/value fields. The message is written as is and not used as a format template
+func DebugWith(method string, m string, kv ...any) {
+	std.writeLog(1, method, m, loglevel.Debug, appendFields(nil, kv))
+}
 
-	msgArr := strings.Split(lmsg, "\n")
-	t := time.Now().Format(constants.TimeFormat)
+// Function TraceWith writes a Trace log along with the given key/value fields. The message is written as is and not used as a format template
+func TraceWith(method string, m string, kv ...any) {
+	std.writeLog(1, method, m, loglevel.Trace, appendFields(nil, kv))
+}
 
-	//Write to File if required
-	if logl >= config.GetConfig().LogLevel {
+// Function AddSink registers an additional output for the default Logger. Logs at or above level are written to it
+func AddSink(s Sink, level loglevel.LogLevel) {
+	std.AddSink(s, level)
+}
 
-		for _, m := range msgArr {
-			l := fmt.Sprintf(mt, t, logl, me, m)
-			fmt.Printf("%s\n", l)
-		}
+// Function RemoveSink unregisters a Sink added to the default Logger with AddSink
+func RemoveSink(s Sink) {
+	std.RemoveSink(s)
+}
 
-	}
+// Function Flush blocks until all logs queued by the default Logger in async mode have been written
+func Flush() {
+	std.Flush()
+}
+
+// Function Close writes any logs queued by the default Logger and closes its log file. Should be called before the program exits when async mode is enabled
+func Close() {
+	std.Close()
+}
 
-	if logl >= config.GetConfig().LogFileLevel {
-		for _, m := range msgArr {
-			l := fmt.Sprintf(mt, t, logl, me, m)
-			filelogger.WriteLogToFile(l)
-		}
-	}
+// Function RefreshConfig causes the Klogger module to refresh its config
+func RefreshConfig() {
+	config.RefreshConfig()
 }
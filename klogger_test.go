@@ -1,20 +1,29 @@
 package klogger
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
 
+	"github.com/jon-kamis/klogger/internal/config"
 	"github.com/jon-kamis/klogger/internal/constants"
 	"github.com/jon-kamis/klogger/internal/filelogger"
 	"github.com/jon-kamis/klogger/pkg/loglevel"
 	"github.com/stretchr/testify/assert"
 )
 
-const logLevelAllFileName = "properties\\test\\klogger-loglevel-all-properties.yml"
-const logLevelErrorFileName = "properties\\test\\klogger-loglevel-error-properties.yml"
+const logLevelAllFileName = "properties/test/klogger-loglevel-all-properties.yml"
+const logLevelErrorFileName = "properties/test/klogger-loglevel-error-properties.yml"
+const enterExitFileName = "properties/test/klogger-enterexit-properties.yml"
 
 func TestEnter(t *testing.T) {
 	os.Setenv("KloggerPropFileName", logLevelAllFileName)
@@ -78,6 +87,58 @@ func TestExit(t *testing.T) {
 	os.RemoveAll("test-logs")
 }
 
+func TestEnterExitLogLevels(t *testing.T) {
+	os.Setenv("KloggerPropFileName", enterExitFileName)
+	os.Setenv(constants.UseCacheEnvName, "false")
+	filelogger.CloseFile()
+	os.RemoveAll("test-logs")
+
+	method := "TestEnterExitLogLevels"
+
+	//Levels should be read from the property file
+	Enter(method)
+	Exit(method)
+
+	f, err := os.ReadFile("test-logs/application-test.log")
+	assert.Nil(t, err)
+
+	l := strings.Split(string(f), "\n")
+	assert.Equal(t, 3, len(l))
+	assert.Equal(t, loglevel.Warn.String(), strings.Split(l[0], " ")[2])
+	assert.Equal(t, loglevel.Error.String(), strings.Split(l[1], " ")[2])
+
+	//Environment variables should override the property file
+	t.Setenv("KloggerEnterLogLevel", "debug")
+	t.Setenv("KloggerExitLogLevel", "1")
+	filelogger.CloseFile()
+	os.RemoveAll("test-logs")
+
+	Enter(method)
+	Exit(method)
+
+	f, err = os.ReadFile("test-logs/application-test.log")
+	assert.Nil(t, err)
+
+	l = strings.Split(string(f), "\n")
+	assert.Equal(t, 3, len(l))
+	assert.Equal(t, loglevel.Debug.String(), strings.Split(l[0], " ")[2])
+	assert.Equal(t, loglevel.Trace.String(), strings.Split(l[1], " ")[2])
+
+	//Passed in levels should still take priority
+	filelogger.CloseFile()
+	os.RemoveAll("test-logs")
+
+	Enter(method, loglevel.Info)
+
+	f, err = os.ReadFile("test-logs/application-test.log")
+	assert.Nil(t, err)
+	assert.Equal(t, loglevel.Info.String(), strings.Split(string(f), " ")[2])
+
+	//Cleanup
+	filelogger.CloseFile()
+	os.RemoveAll("test-logs")
+}
+
 func TestInfo(t *testing.T) {
 	os.Setenv("KloggerPropFileName", logLevelAllFileName)
 	os.Setenv(constants.UseCacheEnvName, "false")
@@ -246,7 +307,7 @@ func TestError(t *testing.T) {
 }
 
 func TestCheckFileRollover(t *testing.T) {
-	os.Setenv("KloggerPropFileName", "properties\\test\\klogger-f-rollover-properties.yml")
+	os.Setenv("KloggerPropFileName", "properties/test/klogger-f-rollover-properties.yml")
 	os.Setenv(constants.UseCacheEnvName, "false")
 	filelogger.CloseFile()
 	os.RemoveAll("test-logs")
@@ -274,3 +335,828 @@ func TestCheckFileRollover(t *testing.T) {
 	//Cleanup
 	os.RemoveAll("test-logs")
 }
+
+func TestNew(t *testing.T) {
+	dir := t.TempDir()
+
+	//Create two loggers writing to separate files with separate levels
+	o1 := DefaultOptions()
+	o1.LogFileDir = dir
+	o1.LogFileName = "audit.log"
+	o1.LogLevel = loglevel.None
+	o1.LogFileLevel = loglevel.All
+
+	o2 := o1
+	o2.LogFileName = "request.log"
+	o2.LogFileLevel = loglevel.Error
+
+	l1 := New(o1)
+	l2 := New(o2)
+	defer l1.Close()
+	defer l2.Close()
+
+	method := "TestNew"
+	l1.Info(method, "audit message")
+	l2.Info(method, "request message")
+	l2.Error(method, "request error")
+
+	f, err := os.ReadFile(dir + "/audit.log")
+	assert.Nil(t, err)
+
+	m := strings.Split(string(f), " ")
+	assert.Equal(t, loglevel.Info.String(), m[2])
+	assert.Equal(t, method, m[3])
+
+	f, err = os.ReadFile(dir + "/request.log")
+	assert.Nil(t, err)
+
+	//Only the error should have been written
+	l := strings.Split(string(f), "\n")
+	assert.Equal(t, 2, len(l))
+
+	m = strings.Split(l[0], " ")
+	assert.Equal(t, loglevel.Error.String(), m[2])
+}
+
+func TestDefaultOptions(t *testing.T) {
+	o := DefaultOptions()
+
+	assert.Equal(t, constants.DefaultLogFileNameValue, o.LogFileName)
+	assert.Equal(t, constants.DefaultLogFileDirValue, o.LogFileDir)
+	assert.Equal(t, int64(constants.DefaultRolloverSize), o.RolloverSize)
+	assert.Equal(t, constants.DefaultLogLevelValue, o.LogLevel)
+}
+
+func TestOptionsLiteral(t *testing.T) {
+	//Unset fields of an Options literal take their default values
+	c := Options{LogFileDir: "x", LogFileLevel: loglevel.Warn}.toConfig()
+
+	assert.Equal(t, "x", c.LogFileDir)
+	assert.Equal(t, loglevel.Warn, c.LogFileLevel)
+	assert.Equal(t, constants.DefaultLogFileNameValue, c.LogFileName)
+	assert.Equal(t, constants.DefaultLogLevelValue, c.LogLevel)
+	assert.Equal(t, constants.DefaultStderrLevelValue, c.StderrLevel)
+	assert.Equal(t, constants.DefaultDoRolloverValue, c.DoRollover)
+	assert.Equal(t, int64(constants.DefaultRolloverSize), c.RolloverSize)
+
+	//Zero values are kept when starting from DefaultOptions
+	o := DefaultOptions()
+	o.LogLevel = loglevel.All
+	o.DoRollover = false
+
+	c = o.toConfig()
+	assert.Equal(t, loglevel.All, c.LogLevel)
+	assert.False(t, c.DoRollover)
+}
+
+func TestWith(t *testing.T) {
+	dir := t.TempDir()
+
+	o := DefaultOptions()
+	o.LogFileDir = dir
+	o.LogLevel = loglevel.None
+	o.LogFileLevel = loglevel.All
+
+	l := New(o)
+	defer l.Close()
+
+	method := "TestWith"
+	wl := l.With("userId", 42, "tenant", "acme corp")
+	wl.Enter(method)
+	wl.InfoWith(method, "first line\nsecond line", "orderId", "A-1")
+	wl.Exit(method)
+
+	//The original logger should not have gained any fields
+	l.Info(method, "no fields")
+
+	//Messages with fields are not format templates, unlike those of the level functions
+	l.InfoWith(method, "disk 100% full", "k", 1)
+	l.Info(method, "%d%% done", 50)
+
+	f, err := os.ReadFile(dir + "/" + o.LogFileName)
+	assert.Nil(t, err)
+
+	lines := strings.Split(string(f), "\n")
+	assert.Equal(t, 8, len(lines))
+
+	assert.True(t, strings.HasSuffix(lines[0], `[ENTER] userId=42 tenant="acme corp"`))
+	assert.True(t, strings.HasSuffix(lines[1], `first line userId=42 tenant="acme corp" orderId=A-1`))
+	assert.True(t, strings.HasSuffix(lines[2], `second line userId=42 tenant="acme corp" orderId=A-1`))
+	assert.True(t, strings.HasSuffix(lines[3], `[EXIT] userId=42 tenant="acme corp"`))
+	assert.True(t, strings.HasSuffix(lines[4], method+" no fields"))
+	assert.True(t, strings.HasSuffix(lines[5], method+" disk 100% full k=1"))
+	assert.True(t, strings.HasSuffix(lines[6], method+" 50% done"))
+}
+
+func TestAppendFields(t *testing.T) {
+	fs := appendFields(nil, []any{"a", 1, Field{Key: "b", Value: 2}, 3, "c"})
+
+	assert.Equal(t, []Field{
+		{Key: "a", Value: 1},
+		{Key: "b", Value: 2},
+		{Key: badKey, Value: 3},
+		{Key: badKey, Value: "c"},
+	}, fs)
+}
+
+func TestJSONFormat(t *testing.T) {
+	dir := t.TempDir()
+
+	o := DefaultOptions()
+	o.LogFileDir = dir
+	o.LogLevel = loglevel.None
+	o.LogFileLevel = loglevel.All
+	o.LogFileFormat = "JSON"
+
+	l := New(o)
+	defer l.Close()
+
+	method := "TestJSONFormat"
+	l.With("userId", 42).InfoWith(method, "first line\nsecond line", "err", errors.New("boom"))
+
+	f, err := os.ReadFile(dir + "/" + o.LogFileName)
+	assert.Nil(t, err)
+
+	//A multi-line message should still be written as a single JSON line
+	lines := strings.Split(string(f), "\n")
+	assert.Equal(t, 2, len(lines))
+
+	var r struct {
+		Time    string
+		Level   string
+		Method  string
+		Message string
+		Fields  map[string]any
+	}
+
+	err = json.Unmarshal([]byte(lines[0]), &r)
+	assert.Nil(t, err)
+
+	assert.Equal(t, loglevel.Info.String(), r.Level)
+	assert.Equal(t, method, r.Method)
+	assert.Equal(t, "first line\nsecond line", r.Message)
+	assert.Equal(t, float64(42), r.Fields["userId"])
+	assert.Equal(t, "boom", r.Fields["err"])
+	assert.NotEmpty(t, r.Time)
+}
+
+func TestFormatRecord(t *testing.T) {
+	r := Record{
+		Time:    time.Date(2024, 2, 15, 10, 30, 0, 0, time.Local),
+		Level:   loglevel.Warn,
+		Method:  "method",
+		Message: "message",
+		Fields:  []Field{{Key: "k", Value: "v"}},
+	}
+
+	assert.Equal(t, "2024-02-15 10:30:00 WARN method message k=v", FormatText(r))
+	assert.Equal(t, `{"time":"2024-02-15 10:30:00","level":"WARN","method":"method","message":"message","fields":{"k":"v"}}`, FormatJSON(r))
+
+	//Multi-line messages are written one line at a time
+	r.Message = "line 1\nline 2"
+	assert.Equal(t, "2024-02-15 10:30:00 WARN method line 1 k=v\n2024-02-15 10:30:00 WARN method line 2 k=v", FormatText(r))
+
+	//Unknown formats fall back to text
+	assert.Equal(t, FormatText(r), formatterFor("xml", config.KloggerConfig{})(r))
+}
+
+func TestSinks(t *testing.T) {
+	dir := t.TempDir()
+
+	o := DefaultOptions()
+	o.LogFileDir = dir
+	o.LogLevel = loglevel.None
+	o.LogFileLevel = loglevel.None
+
+	l := New(o)
+
+	var buf bytes.Buffer
+	ws := NewWriterSink(&buf, FormatJSON)
+	l.AddSink(ws, loglevel.Warn)
+
+	fo := DefaultOptions()
+	fo.LogFileDir = dir
+	fo.LogFileName = "sink.log"
+	fs := NewFileSink(fo, nil)
+	l.AddSink(fs, loglevel.All)
+
+	//Sinks are considered when checking if a level is enabled
+	assert.True(t, l.Enabled(loglevel.Trace))
+
+	method := "TestSinks"
+	l.Info(method, "info message")
+	l.Warn(method, "warn message")
+	l.Flush()
+
+	//The writer sink only receives logs at or above its level
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Equal(t, 1, len(lines))
+	assert.True(t, strings.HasPrefix(lines[0], `{"time":`))
+	assert.True(t, strings.HasSuffix(lines[0], `"level":"WARN","method":"TestSinks","message":"warn message"}`))
+
+	f, err := os.ReadFile(dir + "/sink.log")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, strings.Count(string(f), "\n"))
+
+	//The built-in log file is not written below LogFileLevel
+	_, err = os.Stat(dir + "/" + o.LogFileName)
+	assert.True(t, os.IsNotExist(err))
+
+	//Removed sinks are no longer written to
+	l.RemoveSink(ws)
+	l.RemoveSink(fs)
+	assert.False(t, l.Enabled(loglevel.Trace))
+
+	l.Error(method, "error message")
+	assert.Equal(t, 1, strings.Count(buf.String(), "\n"))
+
+	l.Close()
+	fs.Close()
+}
+
+func TestAsync(t *testing.T) {
+	dir := t.TempDir()
+
+	o := DefaultOptions()
+	o.LogFileDir = dir
+	o.LogLevel = loglevel.None
+	o.LogFileLevel = loglevel.All
+	o.DoAsync = true
+	o.AsyncQueueSize = 4
+
+	l := New(o)
+
+	method := "TestAsync"
+	for i := 0; i < 100; i++ {
+		l.Info(method, "message %d", i)
+	}
+
+	//All queued logs should be written once flushed
+	l.Flush()
+
+	f, err := os.ReadFile(dir + "/" + o.LogFileName)
+	assert.Nil(t, err)
+
+	lines := strings.Split(string(f), "\n")
+	assert.Equal(t, 101, len(lines))
+	assert.True(t, strings.HasSuffix(lines[99], "message 99"))
+
+	//Logs written after Close are written synchronously
+	l.Close()
+	l.Info(method, "after close")
+	l.Close()
+
+	f, err = os.ReadFile(dir + "/" + o.LogFileName)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasSuffix(string(f), "after close\n"))
+}
+
+func TestExitSince(t *testing.T) {
+	dir := t.TempDir()
+
+	o := DefaultOptions()
+	o.LogFileDir = dir
+	o.LogLevel = loglevel.None
+	o.LogFileLevel = loglevel.All
+	o.SlowExitThreshold = 50
+
+	l := New(o)
+	defer l.Close()
+
+	method := "TestExitSince"
+
+	//A fast call is written at the exit log level with its elapsed time
+	func() {
+		defer l.ExitSince(method, l.Enter(method))
+	}()
+
+	//A slow call is escalated to the slow exit log level
+	l.ExitSince(method, time.Now().Add(-time.Second))
+
+	//Passed in levels above the slow exit log level are kept
+	l.ExitSince(method, time.Now().Add(-time.Second), loglevel.Error)
+
+	f, err := os.ReadFile(dir + "/" + o.LogFileName)
+	assert.Nil(t, err)
+
+	lines := strings.Split(string(f), "\n")
+	assert.Equal(t, 5, len(lines))
+
+	m := strings.Split(lines[1], " ")
+	assert.Equal(t, loglevel.Info.String(), m[2])
+	assert.Equal(t, constants.Exit, m[4])
+	assert.True(t, strings.HasPrefix(m[5], constants.ElapsedField+"="))
+
+	m = strings.Split(lines[2], " ")
+	assert.Equal(t, loglevel.Warn.String(), m[2])
+	assert.True(t, strings.HasPrefix(m[5], constants.ElapsedField+"=1"))
+
+	m = strings.Split(lines[3], " ")
+	assert.Equal(t, loglevel.Error.String(), m[2])
+}
+
+func TestCallerDetection(t *testing.T) {
+	dir := t.TempDir()
+
+	o := DefaultOptions()
+	o.LogFileDir = dir
+	o.LogLevel = loglevel.None
+	o.LogFileLevel = loglevel.All
+
+	l := New(o)
+	defer l.Close()
+
+	//Every Logger method should detect the calling method when none is given
+	l.Info("", "info")
+	l.InfoWith("", "info with", "k", "v")
+	l.With("k", "v").Warn("", "warn")
+	l.ExitSince("", l.Enter(""))
+	l.ExitError("", "exit error")
+
+	f, err := os.ReadFile(dir + "/" + o.LogFileName)
+	assert.Nil(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(f)), "\n")
+	assert.Equal(t, 7, len(lines))
+
+	for _, line := range lines {
+		assert.Equal(t, "klogger.TestCallerDetection", strings.Split(line, " ")[3], line)
+	}
+}
+
+func TestCallerDetectionDefault(t *testing.T) {
+	os.Setenv("KloggerPropFileName", logLevelAllFileName)
+	os.Setenv(constants.UseCacheEnvName, "false")
+	filelogger.CloseFile()
+	os.RemoveAll("test-logs")
+
+	//Every package level function should detect the calling method when none is given
+	Info("", "info")
+	DebugWith("", "debug with", "k", "v")
+	ExitSince("", Enter(""))
+	ExitError("", "exit error")
+	With("k", "v").Trace("", "trace")
+
+	f, err := os.ReadFile("test-logs/application-test.log")
+	assert.Nil(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(f)), "\n")
+	assert.Equal(t, 7, len(lines))
+
+	for _, line := range lines {
+		assert.Equal(t, "klogger.TestCallerDetectionDefault", strings.Split(line, " ")[3], line)
+	}
+
+	//Cleanup
+	filelogger.CloseFile()
+	os.RemoveAll("test-logs")
+}
+
+// Function logWrapper stands in for a user's wrapper around klogger
+func logWrapper(l *Logger, msg string) {
+	l.Info("", msg)
+}
+
+func TestCallerSkip(t *testing.T) {
+	dir := t.TempDir()
+
+	o := DefaultOptions()
+	o.LogFileDir = dir
+	o.LogLevel = loglevel.None
+	o.LogFileLevel = loglevel.All
+	o.CallerSkip = 1
+
+	l := New(o)
+	defer l.Close()
+
+	//The wrapper should be skipped
+	logWrapper(l, "wrapped")
+
+	f, err := os.ReadFile(dir + "/" + o.LogFileName)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "klogger.TestCallerSkip", strings.Split(string(f), " ")[3])
+}
+
+func TestStderrLevel(t *testing.T) {
+	o := DefaultOptions()
+	o.LogFileDir = t.TempDir()
+	o.LogLevel = loglevel.Debug
+	o.LogFileLevel = loglevel.None
+	o.StderrLevel = loglevel.Warn
+
+	l := New(o)
+
+	var stdout, stderr bytes.Buffer
+	l.out.console.w = &stdout
+	l.out.stderr.w = &stderr
+
+	method := "TestStderrLevel"
+	l.Trace(method, "trace message")
+	l.Info(method, "info message")
+	l.Warn(method, "warn message")
+	l.Error(method, "error message")
+
+	//Levels below StderrLevel go to stdout and the rest to stderr
+	assert.Equal(t, 1, strings.Count(stdout.String(), "\n"))
+	assert.True(t, strings.HasSuffix(stdout.String(), "info message\n"))
+	assert.Equal(t, 2, strings.Count(stderr.String(), "\n"))
+	assert.True(t, strings.HasSuffix(stderr.String(), "error message\n"))
+
+	//All sends every console log to stderr
+	o.StderrLevel = loglevel.All
+	l = New(o)
+
+	stdout.Reset()
+	stderr.Reset()
+	l.out.console.w = &stdout
+	l.out.stderr.w = &stderr
+
+	l.Debug(method, "debug message")
+
+	assert.Equal(t, "", stdout.String())
+	assert.True(t, strings.HasSuffix(stderr.String(), "debug message\n"))
+}
+
+func TestConsoleColor(t *testing.T) {
+	o := DefaultOptions()
+	o.LogFileDir = t.TempDir()
+	o.LogLevel = loglevel.All
+	o.LogFileLevel = loglevel.All
+	o.ConsoleColor = constants.ConsoleColorAlways
+
+	l := New(o)
+	defer l.Close()
+
+	var stdout bytes.Buffer
+	l.out.console.w = &stdout
+
+	method := "TestConsoleColor"
+	l.Info(method, "message")
+
+	//The level and method columns are colored on stdout
+	assert.True(t, strings.HasSuffix(stdout.String(), " "+ansiGreen+"INFO"+ansiReset+" "+ansiBold+method+ansiReset+" message\n"))
+
+	//The log file is never colored
+	f, err := os.ReadFile(o.LogFileDir + "/" + o.LogFileName)
+	assert.Nil(t, err)
+	assert.False(t, strings.Contains(string(f), "\x1b["))
+
+	//JSON logs are never colored
+	o.LogFormat = constants.FormatJSON
+	l = New(o)
+	stdout.Reset()
+	l.out.console.w = &stdout
+	l.Info(method, "message")
+	assert.False(t, strings.Contains(stdout.String(), "\x1b["))
+}
+
+func TestUseColor(t *testing.T) {
+	tty := func() bool { return true }
+	notTTY := func() bool { return false }
+
+	t.Setenv(constants.NoColorEnvName, "")
+
+	assert.True(t, useColor(constants.ConsoleColorAlways, notTTY))
+	assert.False(t, useColor(constants.ConsoleColorNever, tty))
+	assert.True(t, useColor(constants.ConsoleColorAuto, tty))
+	assert.False(t, useColor(constants.ConsoleColorAuto, notTTY))
+
+	//NO_COLOR only disables automatic coloring
+	t.Setenv(constants.NoColorEnvName, "1")
+
+	assert.False(t, useColor(constants.ConsoleColorAuto, tty))
+	assert.True(t, useColor(constants.ConsoleColorAlways, notTTY))
+}
+
+func TestLogTemplate(t *testing.T) {
+	o := DefaultOptions()
+	o.LogFileDir = t.TempDir()
+	o.LogLevel = loglevel.None
+	o.LogFileLevel = loglevel.All
+	o.LogTemplate = "[{level}] {method} ({caller}) pid={pid} g={goroutine} - {msg}{fields}"
+
+	l := New(o)
+	defer l.Close()
+
+	l.InfoWith("method", "line 1\nline 2", "k", "v")
+	_, _, line, _ := runtime.Caller(0)
+
+	f, err := os.ReadFile(o.LogFileDir + "/" + o.LogFileName)
+	assert.Nil(t, err)
+
+	lines := strings.Split(strings.TrimSuffix(string(f), "\n"), "\n")
+	assert.Equal(t, 2, len(lines))
+
+	//The caller is filled in even though a method name was given
+	prefix := fmt.Sprintf("[INFO] method (klogger_test.go:%d) pid=%d g=", line-1, os.Getpid())
+	assert.True(t, strings.HasPrefix(lines[0], prefix))
+	assert.True(t, strings.HasSuffix(lines[0], " - line 1 k=v"))
+	assert.True(t, strings.HasSuffix(lines[1], " - line 2 k=v"))
+	assert.NotEqual(t, prefix+"0", lines[0][:len(prefix)+1])
+
+	//Invalid templates fall back to the default
+	o.LogTemplate = "{time} {unknown}"
+	assert.Equal(t, config.ParseTemplate(constants.DefaultLogTemplateValue), o.toConfig().Template)
+
+	//An empty template uses the default
+	o.LogTemplate = ""
+	assert.Equal(t, config.ParseTemplate(constants.DefaultLogTemplateValue), o.toConfig().Template)
+}
+
+func TestNewTextFormatter(t *testing.T) {
+	f, err := NewTextFormatter("{level}|{method}|{msg}")
+	assert.Nil(t, err)
+	assert.Equal(t, "WARN|method|message", f(Record{Level: loglevel.Warn, Method: "method", Message: "message"}))
+
+	_, err = NewTextFormatter("{level} {msg")
+	assert.NotNil(t, err)
+}
+
+func TestLogTimeFormat(t *testing.T) {
+	o := DefaultOptions()
+	o.LogFileDir = t.TempDir()
+	o.LogLevel = loglevel.None
+	o.LogFileLevel = loglevel.All
+	o.LogFileFormat = constants.FormatJSON
+	o.LogTimeFormat = "unixMilli"
+
+	l := New(o)
+	defer l.Close()
+
+	start := time.Now().UnixMilli()
+	l.Info("method", "message")
+
+	f, err := os.ReadFile(o.LogFileDir + "/" + o.LogFileName)
+	assert.Nil(t, err)
+
+	//Numeric timestamps are written as JSON numbers
+	var m map[string]any
+	assert.Nil(t, json.Unmarshal(f, &m))
+	assert.InDelta(t, float64(start), m["time"], 1000)
+
+	//Text timestamps use the zone and precision
+	o.LogTimeFormat = "rfc3339"
+	o.LogTimeZone = "utc"
+	o.LogTimePrecision = 3
+	r := Record{Time: time.Date(2024, 2, 15, 10, 30, 0, 123456789, time.Local), Level: loglevel.Info, Method: "method", Message: "message"}
+
+	want := r.Time.UTC().Format("2006-01-02T15:04:05.000Z") + " INFO method message"
+	assert.Equal(t, want, formatterFor(constants.FormatText, o.toConfig())(r))
+}
+
+func TestRotateAndReopen(t *testing.T) {
+	dir := t.TempDir()
+
+	o := DefaultOptions()
+	o.LogFileDir = dir
+	o.LogLevel = loglevel.None
+	o.LogFileLevel = loglevel.All
+	o.DoSizeRollover = false
+
+	l := New(o)
+	defer l.Close()
+
+	fn := dir + "/" + o.LogFileName
+	method := "TestRotateAndReopen"
+
+	//Rotating and reopening is safe while other goroutines log
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				l.Info(method, "concurrent")
+			}
+		}()
+	}
+
+	for i := 0; i < 10; i++ {
+		l.Rotate()
+		l.Reopen()
+	}
+	wg.Wait()
+	l.Rotate()
+
+	//Every log was written to a rolled over file
+	_, err := os.Stat(fn)
+	assert.True(t, os.IsNotExist(err))
+
+	lines := 0
+	files, err := os.ReadDir(dir)
+	assert.Nil(t, err)
+	for _, f := range files {
+		b, err := os.ReadFile(dir + "/" + f.Name())
+		assert.Nil(t, err)
+		lines += strings.Count(string(b), "\n")
+	}
+	assert.Equal(t, 400, lines)
+
+	//Reopen writes to a new file after the log file is moved away
+	l.Info(method, "before move")
+	assert.Nil(t, os.Rename(fn, fn+".moved"))
+	l.Reopen()
+	l.Info(method, "after move")
+
+	b, err := os.ReadFile(fn)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasSuffix(string(b), "after move\n"))
+	assert.Equal(t, 1, strings.Count(string(b), "\n"))
+}
+
+func TestReopenOnSignal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("SIGHUP is not supported on windows")
+	}
+
+	dir := t.TempDir()
+
+	o := DefaultOptions()
+	o.LogFileDir = dir
+	o.LogLevel = loglevel.None
+	o.LogFileLevel = loglevel.All
+
+	l := New(o)
+	defer l.Close()
+
+	stop := l.ReopenOnSignal()
+	defer stop()
+
+	fn := dir + "/" + o.LogFileName
+	method := "TestReopenOnSignal"
+
+	l.Info(method, "before move")
+	assert.Nil(t, os.Rename(fn, fn+".1"))
+
+	p, err := os.FindProcess(os.Getpid())
+	assert.Nil(t, err)
+	assert.Nil(t, p.Signal(syscall.SIGHUP))
+
+	//The log file is recreated once the signal has been handled
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(fn)
+		return err == nil
+	}, 2*time.Second, 10*time.Millisecond)
+
+	l.Info(method, "after move")
+
+	b, err := os.ReadFile(fn)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, strings.Count(string(b), "\n"))
+
+	stop()
+	stop()
+}
+
+func TestFileErrorPolicy(t *testing.T) {
+	method := "TestFileErrorPolicy"
+
+	//A file where the log directory should be stops the log file being opened
+	newBlocked := func(policy string) (*Logger, *bytes.Buffer, *[]error, string) {
+		dir := t.TempDir() + "/logs"
+		assert.Nil(t, os.WriteFile(dir, nil, 0644))
+
+		var errs []error
+
+		o := DefaultOptions()
+		o.LogFileDir = dir
+		o.LogLevel = loglevel.None
+		o.LogFileLevel = loglevel.All
+		o.FileErrorPolicy = policy
+		o.ErrorHandler = func(err error) { errs = append(errs, err) }
+
+		l := New(o)
+
+		var fallback bytes.Buffer
+		l.out.file.fallback = &fallback
+
+		return l, &fallback, &errs, dir
+	}
+
+	//stderr writes the log to stderr instead
+	l, fallback, errs, _ := newBlocked(constants.FileErrorPolicyStderr)
+	l.Info(method, "message")
+
+	assert.True(t, strings.HasSuffix(fallback.String(), "INFO TestFileErrorPolicy message\n"))
+	assert.Equal(t, 1, len(*errs))
+	assert.True(t, strings.Contains((*errs)[0].Error(), "written to stderr"))
+	assert.Equal(t, uint64(1), l.ErrorCount())
+
+	//Copies made by With share the error count
+	l.With("k", "v").Info(method, "message")
+	assert.Equal(t, uint64(2), l.ErrorCount())
+
+	//drop discards the log
+	l, fallback, errs, _ = newBlocked(constants.FileErrorPolicyDrop)
+	l.Info(method, "message")
+
+	assert.Equal(t, "", fallback.String())
+	assert.Equal(t, 1, len(*errs))
+	assert.True(t, strings.HasSuffix((*errs)[0].Error(), "log dropped"))
+
+	//retry writes the log once the log file can be opened, which is not reported as an error
+	l, fallback, errs, dir := newBlocked(constants.FileErrorPolicyRetry)
+
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		os.Remove(dir)
+	}()
+
+	l.Info(method, "message")
+	l.Close()
+
+	assert.Equal(t, "", fallback.String())
+	assert.Equal(t, 0, len(*errs))
+	assert.Equal(t, uint64(0), l.ErrorCount())
+
+	b, err := os.ReadFile(dir + "/" + DefaultOptions().LogFileName)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasSuffix(string(b), "message\n"))
+
+	//retry drops the log once it runs out of retries
+	l, _, errs, _ = newBlocked(constants.FileErrorPolicyRetry)
+	l.Info(method, "message")
+
+	assert.Equal(t, 1, len(*errs))
+	assert.True(t, strings.HasSuffix((*errs)[0].Error(), "log dropped after 3 retries"))
+}
+
+func TestErrorHandlerLogs(t *testing.T) {
+	method := "TestErrorHandlerLogs"
+
+	//A file where the log directory should be stops the log file being opened
+	dir := t.TempDir() + "/logs"
+	assert.Nil(t, os.WriteFile(dir, nil, 0644))
+
+	o := DefaultOptions()
+	o.LogFileDir = dir
+	o.LogLevel = loglevel.None
+	o.LogFileLevel = loglevel.All
+	o.FileErrorPolicy = constants.FileErrorPolicyDrop
+	o.DoAsync = true
+	o.AsyncQueueSize = 1
+	o.AsyncOverflowPolicy = constants.AsyncOverflowBlock
+
+	l := New(o)
+
+	var calls atomic.Int32
+
+	//The handler runs on the goroutine draining the queue and logs through the same Logger while the queue is full
+	l.SetErrorHandler(func(err error) {
+		calls.Add(1)
+		l.Warn(method, "failed to write a log: %v", err)
+	})
+
+	done := make(chan struct{})
+
+	go func() {
+		for i := 0; i < 5; i++ {
+			l.Info(method, "message")
+		}
+
+		l.Close()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("logging from the error handler deadlocked")
+	}
+
+	//Errors from logs written by the handler are not passed to the handler again
+	assert.Equal(t, int32(5), calls.Load())
+	assert.Equal(t, uint64(10), l.ErrorCount())
+}
+
+func TestSinkFormats(t *testing.T) {
+	r := Record{
+		Time:    time.Date(2024, 2, 15, 10, 37, 42, 0, time.UTC),
+		Level:   loglevel.Info,
+		Method:  "TestSinkFormats",
+		Message: "message",
+	}
+
+	o := DefaultOptions()
+	o.LogFileDir = t.TempDir()
+	o.LogFileFormat = constants.FormatJSON
+	o.LogTimeFormat = "unix"
+
+	//File sinks without a Formatter use the format and timestamp settings of their Options
+	fs := NewFileSink(o, nil)
+	assert.Nil(t, fs.Write(r))
+	assert.Nil(t, fs.Close())
+
+	f, err := os.ReadFile(o.LogFileDir + "/" + o.LogFileName)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"time":1707993462,"level":"INFO","method":"TestSinkFormats","message":"message"}`+"\n", string(f))
+
+	//NewFormatter uses the template and timestamp settings of its Options
+	o.LogTemplate = "{time} [{level}] {msg}"
+	o.LogTimeFormat = "rfc3339"
+	o.LogTimeZone = "UTC"
+
+	assert.Equal(t, "2024-02-15T10:37:42Z [INFO] message", NewFormatter("TEXT", o)(r))
+	assert.Equal(t, `{"time":"2024-02-15T10:37:42Z","level":"INFO","method":"TestSinkFormats","message":"message"}`, NewFormatter(constants.FormatJSON, o)(r))
+}
@@ -0,0 +1,429 @@
+package klogger
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jon-kamis/klogger/internal/asyncqueue"
+	"github.com/jon-kamis/klogger/internal/config"
+	"github.com/jon-kamis/klogger/internal/constants"
+	"github.com/jon-kamis/klogger/internal/filelogger"
+	"github.com/jon-kamis/klogger/internal/linetemplate"
+	"github.com/jon-kamis/klogger/internal/utils"
+	"github.com/jon-kamis/klogger/pkg/loglevel"
+)
+
+// Type Logger writes logs to stdout, a log file and any registered Sinks using its own levels, file destination and rollover state
+type Logger struct {
+	conf   func() config.KloggerConfig
+	out    *outputs
+	fields []Field //Fields attached to every log written by this Logger
+}
+
+// Type outputs holds the writers shared between a Logger and the copies of it made by With
+type outputs struct {
+	console   *writerSink //Writes to stdout using the LogLevel and LogFormat properties
+	stderr    *writerSink //Writes console logs at or above the StderrLevel property to stderr
+	file      *fileSink   //Writes to the log file using the LogFileLevel and LogFileFormat properties
+	sinksMu   sync.RWMutex
+	sinks     []sinkEntry //Sinks registered with AddSink
+	queueOnce sync.Once
+	queue     *asyncqueue.Queue[Record] //Only set when DoAsync is enabled
+	errCount  atomic.Uint64             //Errors reported since the Logger was created
+	onError   atomic.Pointer[func(error)]
+	handlers  atomic.Int32 //The number of goroutines running the error handler
+	handling  sync.Map     //The ids of the goroutines running the error handler
+}
+
+// Function New returns a Logger configured by the given Options. Fields left unset in an Options literal take their default values. Each Logger should write to its own log file
+func New(o Options) *Logger {
+	c := o.toConfig()
+	conf := func() config.KloggerConfig { return c }
+
+	lg := newLogger(conf, filelogger.New(conf))
+	lg.SetErrorHandler(o.ErrorHandler)
+
+	return lg
+}
+
+// Function newLogger returns a Logger reading its settings from conf and writing its log file with fl
+func newLogger(conf func() config.KloggerConfig, fl *filelogger.FileLogger) *Logger {
+	lg := &Logger{
+		conf: conf,
+		out: &outputs{
+			console: &writerSink{w: os.Stdout, format: consoleFormatter(conf, os.Stdout)},
+			stderr:  &writerSink{w: os.Stderr, format: consoleFormatter(conf, os.Stderr)},
+			file: &fileSink{
+				file:     fl,
+				conf:     conf,
+				fallback: os.Stderr,
+				format:   func(r Record) string { c := conf(); return formatterFor(c.LogFileFormat, c)(r) },
+			},
+		},
+	}
+
+	//Errors from rolling the log file over and its background goroutines are counted with the Logger's own errors
+	fl.SetErrorHandler(lg.reportError)
+
+	return lg
+}
+
+// Function Enter writes a log used to declare where a method begins execution
+// method - The method to write an enter log for
+// l - The log levels to write to. If this is not set than the default log level for Enter logs is used
+// returns the time in which the log is written to track exit times if desired
+func (lg *Logger) Enter(method string, l ...loglevel.LogLevel) time.Time {
+	return lg.enter(1, method, l)
+}
+
+// Function Exit writes a log used to declare where a method ends execution
+// method - The method to write an exit log for
+// l - The log levels to write to. If this is not set than the default log level for Exit logs is used
+func (lg *Logger) Exit(method string, l ...loglevel.LogLevel) {
+	lg.exit(1, method, l)
+}
+
+// Function ExitSince writes a log used to declare where a method ends execution along with the time elapsed since start
+// If the elapsed time exceeds SlowExitThreshold the log is written at no lower than SlowExitLogLevel
+// method - The method to write an exit log for
+// start - The time the method began, such as the time returned by Enter
+// l - The log levels to write to. If this is not set than the default log level for Exit logs is used
+func (lg *Logger) ExitSince(method string, start time.Time, l ...loglevel.LogLevel) {
+	lg.exitSince(1, method, start, l)
+}
+
+// Function Error writes a log with Error log level declaring which method the error occured in
+func (lg *Logger) Error(method string, m string, args ...any) {
+	lg.writeLogf(1, method, m, loglevel.Error, args...)
+}
+
+// Function Warn writes a log with Warn log level declaring which method the warning occured in
+func (lg *Logger) Warn(method string, m string, args ...any) {
+	lg.writeLogf(1, method, m, loglevel.Warn, args...)
+}
+
+// Function ExitError combines the Exit and Error functions together
+func (lg *Logger) ExitError(method string, msg string, args ...any) {
+	lg.writeLogf(1, method, msg, loglevel.Error, args...)
+	lg.exit(1, method, nil)
+}
+
+// Function Info writes a log with Info log level containing a custom message and the method that the message is coming from
+func (lg *Logger) Info(method string, m string, args ...any) {
+	lg.writeLogf(1, method, m, loglevel.Info, args...)
+}
+
+// Function Debug writes a log with Debug log level containing a custom message and the method that the message is coming from
+func (lg *Logger) Debug(method string, m string, args ...any) {
+	lg.writeLogf(1, method, m, loglevel.Debug, args...)
+}
+
+// Function Trace writes a log with Trace log level containing a custom message and the method that the message is coming from
+func (lg *Logger) Trace(method string, m string, args ...any) {
+	lg.writeLogf(1, method, m, loglevel.Trace, args...)
+}
+
+// Function ErrorWith writes a log with Error log level along with the given key/value fields. The message is written as is and not used as a format template
+func (lg *Logger) ErrorWith(method string, m string, kv ...any) {
+	lg.writeLog(1, method, m, loglevel.Error, appendFields(nil, kv))
+}
+
+// Function WarnWith writes a log with Warn log level along with the given key/value fields. The message is written as is and not used as a format template
+func (lg *Logger) WarnWith(method string, m string, kv ...any) {
+	lg.writeLog(1, method, m, loglevel.Warn, appendFields(nil, kv))
+}
+
+// Function InfoWith writes a log with Info log level along with the given key/value fields. The message is written as is and not used as a format template
+func (lg *Logger) InfoWith(method string, m string, kv ...any) {
+	lg.writeLog(1, method, m, loglevel.Info, appendFields(nil, kv))
+}
+
+// Function DebugWith writes a log with Debug log level along with the given key/value fields. The message is written as is and not used as a format template
+func (lg *Logger) DebugWith(method string, m string, kv ...any) {
+	lg.writeLog(1, method, m, loglevel.Debug, appendFields(nil, kv))
+}
+
+// Function TraceWith writes a log with Trace log level along with the given key/value fields. The message is written as is and not used as a format template
+func (lg *Logger) TraceWith(method string, m string, kv ...any) {
+	lg.writeLog(1, method, m, loglevel.Trace, appendFields(nil, kv))
+}
+
+// Function Log writes a message with the given log level and fields. Unlike the level functions the message is written as is and not used as a format template
+func (lg *Logger) Log(logl loglevel.LogLevel, method string, msg string, fields ...Field) {
+	lg.LogRecord(Record{
+		Level:   logl,
+		Method:  method,
+		Message: msg,
+		Fields:  fields,
+	})
+}
+
+// Function LogRecord writes a Record built by the caller, such as one passed on from another logging library, keeping its time, file and line. A zero Time is set to the current time, the goroutine is set to the calling goroutine when the LogTemplate uses it, and the Logger's fields are written before those of the Record
+func (lg *Logger) LogRecord(r Record) {
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+
+	//The goroutine is looked up here as async logs are formatted on a background goroutine
+	if r.Goroutine == 0 && lg.conf().Template.Uses(linetemplate.Goroutine) {
+		r.Goroutine = utils.GoroutineID()
+	}
+
+	r.Fields = append(lg.fields[:len(lg.fields):len(lg.fields)], r.Fields...)
+
+	lg.log(r)
+}
+
+// Function Enabled returns true if a log with the given log level would be written to stdout, the log file or any registered Sink
+func (lg *Logger) Enabled(logl loglevel.LogLevel) bool {
+	c := lg.conf()
+
+	if logl >= c.LogLevel || logl >= c.LogFileLevel {
+		return true
+	}
+
+	lg.out.sinksMu.RLock()
+	defer lg.out.sinksMu.RUnlock()
+
+	for _, se := range lg.out.sinks {
+		if logl >= se.level {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Function AddSink registers an additional output for the Logger and the copies made of it by With. Logs at or above level are written to it
+func (lg *Logger) AddSink(s Sink, level loglevel.LogLevel) {
+	lg.out.sinksMu.Lock()
+	defer lg.out.sinksMu.Unlock()
+
+	lg.out.sinks = append(lg.out.sinks[:len(lg.out.sinks):len(lg.out.sinks)], sinkEntry{sink: s, level: level})
+}
+
+// Function RemoveSink unregisters a Sink added with AddSink. The Sink is not closed
+func (lg *Logger) RemoveSink(s Sink) {
+	lg.out.sinksMu.Lock()
+	defer lg.out.sinksMu.Unlock()
+
+	sinks := make([]sinkEntry, 0, len(lg.out.sinks))
+
+	for _, se := range lg.out.sinks {
+		if se.sink != s {
+			sinks = append(sinks, se)
+		}
+	}
+
+	lg.out.sinks = sinks
+}
+
+// Function registeredSinks returns the Sinks registered with AddSink
+func (lg *Logger) registeredSinks() []sinkEntry {
+	lg.out.sinksMu.RLock()
+	defer lg.out.sinksMu.RUnlock()
+
+	return lg.out.sinks
+}
+
+// Function Flush blocks until all logs queued in async mode have been written, syncs the log file to disk and flushes registered Sinks
+func (lg *Logger) Flush() {
+	if q := lg.asyncQueue(); q != nil {
+		q.Flush()
+	}
+
+	lg.reportError(lg.out.file.Flush())
+
+	for _, se := range lg.registeredSinks() {
+		lg.reportError(se.sink.Flush())
+	}
+}
+
+// Function Close writes any queued logs, stops async mode, closes the log file and closes registered Sinks. Logs written after Close are written synchronously and reopen the log file
+func (lg *Logger) Close() {
+	if q := lg.asyncQueue(); q != nil {
+		q.Close()
+	}
+
+	lg.reportError(lg.out.file.Close())
+
+	for _, se := range lg.registeredSinks() {
+		lg.reportError(se.sink.Close())
+	}
+}
+
+// Function enter writes ENTER logs. See Enter
+// skip - the number of klogger functions between the caller of enter and the user's code
+func (lg *Logger) enter(skip int, method string, l []loglevel.LogLevel) time.Time {
+
+	c := lg.conf()
+
+	if !c.DoEnterExitLogs {
+		return time.Now()
+	}
+
+	if len(l) > 0 {
+		for _, ll := range l {
+			lg.writeLog(skip+1, method, constants.Enter, ll, nil)
+		}
+	} else {
+		lg.writeLog(skip+1, method, constants.Enter, c.EnterLogLevel, nil)
+	}
+
+	return time.Now()
+}
+
+// Function exit writes EXIT logs. See Exit
+// skip - the number of klogger functions between the caller of exit and the user's code
+func (lg *Logger) exit(skip int, method string, l []loglevel.LogLevel) {
+
+	c := lg.conf()
+
+	if !c.DoEnterExitLogs {
+		return
+	}
+
+	if len(l) > 0 {
+		for _, ll := range l {
+			lg.writeLog(skip+1, method, constants.Exit, ll, nil)
+		}
+	} else {
+		lg.writeLog(skip+1, method, constants.Exit, c.ExitLogLevel, nil)
+	}
+}
+
+// Function exitSince writes EXIT logs with the elapsed time. See ExitSince
+// skip - the number of klogger functions between the caller of exitSince and the user's code
+func (lg *Logger) exitSince(skip int, method string, start time.Time, l []loglevel.LogLevel) {
+
+	c := lg.conf()
+
+	if !c.DoEnterExitLogs {
+		return
+	}
+
+	d := time.Since(start)
+	slow := c.SlowExitThreshold > 0 && d > time.Duration(c.SlowExitThreshold)*time.Millisecond
+
+	if len(l) == 0 {
+		l = []loglevel.LogLevel{c.ExitLogLevel}
+	}
+
+	fs := []Field{{Key: constants.ElapsedField, Value: d.String()}}
+
+	for _, ll := range l {
+		if slow && ll < c.SlowExitLogLevel {
+			ll = c.SlowExitLogLevel
+		}
+
+		lg.writeLog(skip+1, method, constants.Exit, ll, fs)
+	}
+}
+
+// Function writeLogf fills in the parameters of a format template and writes the result with writeLog
+// skip - the number of klogger functions between the caller of writeLogf and the user's code, used to find the calling method
+func (lg *Logger) writeLogf(skip int, me string, format string, logl loglevel.LogLevel, args ...any) {
+
+	//Skip formatting when nothing will be logged
+	if !lg.Enabled(logl) {
+		return
+	}
+
+	lg.writeLog(skip+1, me, fmt.Sprintf(format, args...), logl, nil)
+}
+
+// Function writeLog writes a log to stdout and a log file. The message is written as is
+// skip - the number of klogger functions between the caller of writeLog and the user's code, used to find the calling method
+// me - method. If empty the calling method is used
+// msg - message to log
+// fs - fields to log in addition to those attached to the Logger
+func (lg *Logger) writeLog(skip int, me string, msg string, logl loglevel.LogLevel, fs []Field) {
+
+	//Check if anything will be logged by this command
+	if !lg.Enabled(logl) {
+		return
+	}
+
+	r := Record{
+		Time:    time.Now(),
+		Level:   logl,
+		Method:  me,
+		Message: msg,
+		Fields:  append(lg.fields[:len(lg.fields):len(lg.fields)], fs...),
+	}
+
+	c := lg.conf()
+
+	if me == "" {
+		r.Method, r.File, r.Line = caller(skip + c.CallerSkip)
+	} else if c.Template.Uses(linetemplate.File, linetemplate.Line, linetemplate.Caller) {
+		_, r.File, r.Line = caller(skip + c.CallerSkip)
+	}
+
+	//The goroutine is looked up here as async logs are formatted on a background goroutine
+	if c.Template.Uses(linetemplate.Goroutine) {
+		r.Goroutine = utils.GoroutineID()
+	}
+
+	lg.log(r)
+}
+
+// Function log writes a Record, queueing it first when async mode is enabled
+func (lg *Logger) log(r Record) {
+	//Logs from the error handler may be on the goroutine draining the queue, which would wait forever for room in a full queue
+	if q := lg.asyncQueue(); q != nil && !lg.inErrorHandler() && q.Enqueue(r) {
+		return
+	}
+
+	lg.write(r)
+}
+
+// Function write writes a Record to stdout or stderr, the log file and registered Sinks based on their log levels
+func (lg *Logger) write(r Record) {
+
+	c := lg.conf()
+
+	if r.Level >= c.LogLevel {
+		if r.Level >= c.StderrLevel {
+			lg.reportError(lg.out.stderr.Write(r))
+		} else {
+			lg.reportError(lg.out.console.Write(r))
+		}
+	}
+
+	if r.Level >= c.LogFileLevel {
+		lg.reportError(lg.out.file.Write(r))
+	}
+
+	for _, se := range lg.registeredSinks() {
+		if r.Level >= se.level {
+			lg.reportError(se.sink.Write(r))
+		}
+	}
+}
+
+// Function asyncQueue returns the queue used in async mode, starting it on first use. Returns nil if async mode is disabled
+func (lg *Logger) asyncQueue() *asyncqueue.Queue[Record] {
+	lg.out.queueOnce.Do(func() {
+		c := lg.conf()
+
+		if c.DoAsync {
+			lg.out.queue = asyncqueue.New(c.AsyncQueueSize, c.AsyncOverflowPolicy, lg.write, lg.writeDropped)
+		}
+	})
+
+	return lg.out.queue
+}
+
+// Function writeDropped writes a warning that logs were dropped because the async queue was full
+func (lg *Logger) writeDropped(n int64) {
+	lg.write(Record{
+		Time:    time.Now(),
+		Level:   loglevel.Warn,
+		Method:  constants.KloggerMethod,
+		Message: fmt.Sprintf("dropped %d logs because the async queue was full", n),
+	})
+}
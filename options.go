@@ -0,0 +1,173 @@
+package klogger
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/jon-kamis/klogger/internal/config"
+	"github.com/jon-kamis/klogger/pkg/loglevel"
+)
+
+// Type Options holds the settings used to construct an independent Logger with New
+// Fields left at their zero value in Options which were not made by DefaultOptions take their default values. Start from DefaultOptions to set a field to its zero value, such as a LogLevel of All or DoRollover false
+type Options struct {
+	LogFileName         string            //The name of the file to write logs to
+	LogFileDir          string            //The directory to write log files in
+	DoRollover          bool              //Determines whether to rollover log files
+	DoSizeRollover      bool              //Determines whether to rollover based on the size of the log file
+	DoDateRollover      bool              //Determines whether to rollover based on the current date
+	RolloverSize        int64             //The size limit in bytes for a log file to reach before rolling over
+	LogLevel            loglevel.LogLevel //The log level for stdout
+	LogFileLevel        loglevel.LogLevel //The log level for log files
+	EnterLogLevel       loglevel.LogLevel //The log level to be used for ENTER logs
+	ExitLogLevel        loglevel.LogLevel //The log level to be used for EXIT logs
+	DoEnterExitLogs     bool              //Determines whether to write or ignore ENTER and EXIT logs
+	LogFormat           string            //The output format for stdout, either text or json
+	LogFileFormat       string            //The output format for log files, either text or json
+	DoAsync             bool              //Determines whether logs are queued and written by a background goroutine
+	AsyncQueueSize      int               //The number of logs which can be queued when DoAsync is set
+	AsyncOverflowPolicy string            //What to do when the async queue is full. One of block, dropNewest or dropOldest
+	SyncPolicy          string            //When the log file is synced to disk. One of always, interval, never or onLevel
+	SyncInterval        int               //The number of milliseconds between syncs when SyncPolicy is interval
+	SyncLogLevel        loglevel.LogLevel //The lowest log level which causes a sync when SyncPolicy is onLevel
+	SlowExitThreshold   int               //The number of milliseconds after which ExitSince logs are escalated to SlowExitLogLevel. 0 disables escalation
+	SlowExitLogLevel    loglevel.LogLevel //The log level ExitSince logs are escalated to when SlowExitThreshold is exceeded
+	CallerSkip          int               //The number of additional functions to skip when detecting the calling method, for use by wrappers of klogger
+	StderrLevel         loglevel.LogLevel //The lowest log level written to stderr instead of stdout. None writes all console logs to stdout and All writes them all to stderr
+	ConsoleColor        string            //Whether console logs are colored. One of auto, always or never. auto colors logs only when writing to a terminal and NO_COLOR is not set
+	LogTemplate         string            //The template for each line of text logs. See the README for the available tokens
+	LogTimeFormat       string            //The layout of timestamps. A Go time layout or one of rfc3339, rfc3339nano, unix or unixmilli
+	LogTimeZone         string            //The time zone timestamps and rollover dates are written in. Local, UTC or an IANA time zone name
+	LogTimePrecision    int               //The number of fractional second digits written with timestamps, between 0 and 9
+	RolloverInterval    string            //How often log files are rolled over when DoDateRollover is enabled. One of minute, hour, day, week, month, @hourly, @daily, @weekly, @monthly or @every followed by a duration
+	MaxBackups          int               //The number of rolled over log files to keep. 0 keeps all of them
+	MaxAgeDays          int               //The number of days to keep rolled over log files for. 0 keeps them regardless of age
+	MaxTotalSize        int64             //The total size in bytes of rolled over log files to keep. 0 keeps them regardless of size
+	Compression         string            //The codec rolled over log files are compressed with in the background. none or gzip, or the name of a codec added with RegisterCodec
+	RolloverNamePattern string            //The naming pattern for rolled over log files, made of the tokens {base}, {date}, {n} and {ext}
+	RotateOnStartup     bool              //Determines whether a log file left by an earlier run is rolled over before the first log is written
+	FileCheckInterval   int               //The number of milliseconds between checks that the log file has not been deleted or replaced. 0 disables the check
+	FileErrorPolicy     string            //What to do with a log which cannot be written to the log file. One of stderr, retry or drop
+	ErrorHandler        func(error)       //Receives errors from writing logs. Has no matching property. If nil errors are printed to stderr
+
+	fromDefaults bool //Set by DefaultOptions, in which case zero values are kept
+}
+
+// Function DefaultOptions returns Options populated with the default property values
+func DefaultOptions() Options {
+	o := optionsFromConfig(config.Default())
+	o.fromDefaults = true
+
+	return o
+}
+
+// Function withDefaults returns o with every zero valued field set to its default, unless o was made by DefaultOptions
+func (o Options) withDefaults() Options {
+	if o.fromDefaults {
+		return o
+	}
+
+	d := DefaultOptions()
+	ov := reflect.ValueOf(&o).Elem()
+	dv := reflect.ValueOf(d)
+
+	for i := 0; i < ov.NumField(); i++ {
+		if f := ov.Field(i); f.CanSet() && f.IsZero() {
+			f.Set(dv.Field(i))
+		}
+	}
+
+	return o
+}
+
+// Function optionsFromConfig converts an internal config into Options
+func optionsFromConfig(c config.KloggerConfig) Options {
+	return Options{
+		LogFileName:         c.LogFileName,
+		LogFileDir:          c.LogFileDir,
+		DoRollover:          c.DoRollover,
+		DoSizeRollover:      c.DoSizeRollover,
+		DoDateRollover:      c.DoDateRollover,
+		RolloverSize:        c.RolloverSize,
+		LogLevel:            c.LogLevel,
+		LogFileLevel:        c.LogFileLevel,
+		EnterLogLevel:       c.EnterLogLevel,
+		ExitLogLevel:        c.ExitLogLevel,
+		DoEnterExitLogs:     c.DoEnterExitLogs,
+		LogFormat:           c.LogFormat,
+		LogFileFormat:       c.LogFileFormat,
+		DoAsync:             c.DoAsync,
+		AsyncQueueSize:      c.AsyncQueueSize,
+		AsyncOverflowPolicy: c.AsyncOverflowPolicy,
+		SyncPolicy:          c.SyncPolicy,
+		SyncInterval:        c.SyncInterval,
+		SyncLogLevel:        c.SyncLogLevel,
+		SlowExitThreshold:   c.SlowExitThreshold,
+		SlowExitLogLevel:    c.SlowExitLogLevel,
+		CallerSkip:          c.CallerSkip,
+		StderrLevel:         c.StderrLevel,
+		ConsoleColor:        c.ConsoleColor,
+		LogTemplate:         c.LogTemplate,
+		LogTimeFormat:       c.LogTimeFormat,
+		LogTimeZone:         c.LogTimeZone,
+		LogTimePrecision:    c.LogTimePrecision,
+		RolloverInterval:    c.RolloverInterval,
+		MaxBackups:          c.MaxBackups,
+		MaxAgeDays:          c.MaxAgeDays,
+		MaxTotalSize:        c.MaxTotalSize,
+		Compression:         c.Compression,
+		RolloverNamePattern: c.RolloverNamePattern,
+		RotateOnStartup:     c.RotateOnStartup,
+		FileCheckInterval:   c.FileCheckInterval,
+		FileErrorPolicy:     c.FileErrorPolicy,
+	}
+}
+
+// Function toConfig converts Options into the internal config used by the writers
+func (o Options) toConfig() config.KloggerConfig {
+	o = o.withDefaults()
+
+	return config.KloggerConfig{
+		LogFileName:         o.LogFileName,
+		LogFileDir:          o.LogFileDir,
+		DoRollover:          o.DoRollover,
+		DoSizeRollover:      o.DoSizeRollover,
+		DoDateRollover:      o.DoDateRollover,
+		RolloverSize:        o.RolloverSize,
+		LogLevel:            o.LogLevel,
+		LogFileLevel:        o.LogFileLevel,
+		EnterLogLevel:       o.EnterLogLevel,
+		ExitLogLevel:        o.ExitLogLevel,
+		DoEnterExitLogs:     o.DoEnterExitLogs,
+		LogFormat:           strings.ToLower(o.LogFormat),
+		LogFileFormat:       strings.ToLower(o.LogFileFormat),
+		DoAsync:             o.DoAsync,
+		AsyncQueueSize:      o.AsyncQueueSize,
+		AsyncOverflowPolicy: strings.ToLower(o.AsyncOverflowPolicy),
+		SyncPolicy:          strings.ToLower(o.SyncPolicy),
+		SyncInterval:        o.SyncInterval,
+		SyncLogLevel:        o.SyncLogLevel,
+		SlowExitThreshold:   o.SlowExitThreshold,
+		SlowExitLogLevel:    o.SlowExitLogLevel,
+		CallerSkip:          o.CallerSkip,
+		StderrLevel:         o.StderrLevel,
+		ConsoleColor:        strings.ToLower(o.ConsoleColor),
+		LogTemplate:         o.LogTemplate,
+		Template:            config.ParseTemplate(o.LogTemplate),
+		LogTimeFormat:       o.LogTimeFormat,
+		LogTimeZone:         o.LogTimeZone,
+		LogTimePrecision:    o.LogTimePrecision,
+		Time:                config.ParseTimeFormat(o.LogTimeFormat, o.LogTimeZone, o.LogTimePrecision),
+		RolloverInterval:    strings.ToLower(o.RolloverInterval),
+		Rollover:            config.ParseRolloverInterval(o.RolloverInterval),
+		MaxBackups:          o.MaxBackups,
+		MaxAgeDays:          o.MaxAgeDays,
+		MaxTotalSize:        o.MaxTotalSize,
+		Compression:         strings.ToLower(o.Compression),
+		RolloverNamePattern: o.RolloverNamePattern,
+		RolloverName:        config.ParseRolloverNamePattern(o.RolloverNamePattern),
+		RotateOnStartup:     o.RotateOnStartup,
+		FileCheckInterval:   o.FileCheckInterval,
+		FileErrorPolicy:     strings.ToLower(o.FileErrorPolicy),
+	}
+}
@@ -0,0 +1,138 @@
+// Package kslog provides a log/slog Handler which writes through a klogger Logger
+package kslog
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+
+	"github.com/jon-kamis/klogger"
+	"github.com/jon-kamis/klogger/internal/utils"
+	"github.com/jon-kamis/klogger/pkg/loglevel"
+)
+
+// Type Handler is a slog.Handler which writes records using the levels, outputs and file rollover of a klogger Logger
+type Handler struct {
+	lg     *klogger.Logger
+	prefix string //Prefix added to attribute keys by WithGroup
+}
+
+// Function NewHandler returns a Handler which writes to the given Logger. If lg is nil the default klogger Logger is used
+func NewHandler(lg *klogger.Logger) *Handler {
+	if lg == nil {
+		lg = klogger.Default()
+	}
+
+	return &Handler{lg: lg}
+}
+
+// Function Level maps a slog level onto the closest klogger LogLevel
+func Level(l slog.Level) loglevel.LogLevel {
+	switch {
+	case l < slog.LevelDebug:
+		return loglevel.Trace
+	case l < slog.LevelInfo:
+		return loglevel.Debug
+	case l < slog.LevelWarn:
+		return loglevel.Info
+	case l < slog.LevelError:
+		return loglevel.Warn
+	}
+	return loglevel.Error
+}
+
+// Function Enabled reports whether the Logger writes records with the given level to any output
+func (h *Handler) Enabled(_ context.Context, l slog.Level) bool {
+	return h.lg.Enabled(Level(l))
+}
+
+// Function Handle writes a record to the Logger with the record's time. The method, file and line are those of the function that created the record
+func (h *Handler) Handle(_ context.Context, r slog.Record) error {
+	fs := make([]klogger.Field, 0, r.NumAttrs())
+
+	r.Attrs(func(a slog.Attr) bool {
+		fs = appendAttr(fs, h.prefix, a)
+		return true
+	})
+
+	me, file, line := caller(r.PC)
+
+	//Records keep their own time, which LogRecord replaces with the current time when it is zero
+	h.lg.LogRecord(klogger.Record{
+		Time:    r.Time,
+		Level:   Level(r.Level),
+		Method:  me,
+		File:    file,
+		Line:    line,
+		Message: r.Message,
+		Fields:  fs,
+	})
+
+	return nil
+}
+
+// Function WithAttrs returns a Handler which writes the given attributes with every record
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	var kv []any
+
+	for _, f := range appendAttrs(nil, h.prefix, attrs) {
+		kv = append(kv, f)
+	}
+
+	return &Handler{lg: h.lg.With(kv...), prefix: h.prefix}
+}
+
+// Function WithGroup returns a Handler which qualifies the keys of all following attributes with the group name
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	return &Handler{lg: h.lg, prefix: h.prefix + name + "."}
+}
+
+// Function appendAttrs converts slog attributes into klogger fields
+func appendAttrs(fs []klogger.Field, prefix string, attrs []slog.Attr) []klogger.Field {
+	for _, a := range attrs {
+		fs = appendAttr(fs, prefix, a)
+	}
+
+	return fs
+}
+
+// Function appendAttr converts a single slog attribute into klogger fields. Groups are flattened into dotted keys
+func appendAttr(fs []klogger.Field, prefix string, a slog.Attr) []klogger.Field {
+	a.Value = a.Value.Resolve()
+
+	if a.Equal(slog.Attr{}) {
+		return fs
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		gp := prefix
+
+		//Attributes of a group with no key are inlined
+		if a.Key != "" {
+			gp = prefix + a.Key + "."
+		}
+
+		return appendAttrs(fs, gp, a.Value.Group())
+	}
+
+	return append(fs, klogger.Field{Key: prefix + a.Key, Value: a.Value.Any()})
+}
+
+// Function caller returns the name of the function at pc without its package path, along with its file and line
+func caller(pc uintptr) (string, string, int) {
+	if pc == 0 {
+		return "", "", 0
+	}
+
+	f, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+
+	return utils.ShortFuncName(f.Function), f.File, f.Line
+}
@@ -0,0 +1,114 @@
+package kslog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jon-kamis/klogger"
+	"github.com/jon-kamis/klogger/pkg/loglevel"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestLogger(t *testing.T, fl loglevel.LogLevel) (*klogger.Logger, string) {
+	o := klogger.DefaultOptions()
+	o.LogFileDir = t.TempDir()
+	o.LogLevel = loglevel.None
+	o.LogFileLevel = fl
+
+	l := klogger.New(o)
+	t.Cleanup(l.Close)
+
+	return l, o.LogFileDir + "/" + o.LogFileName
+}
+
+func TestLevel(t *testing.T) {
+	assert.Equal(t, loglevel.Trace, Level(slog.LevelDebug-1))
+	assert.Equal(t, loglevel.Debug, Level(slog.LevelDebug))
+	assert.Equal(t, loglevel.Info, Level(slog.LevelInfo))
+	assert.Equal(t, loglevel.Info, Level(slog.LevelInfo+2))
+	assert.Equal(t, loglevel.Warn, Level(slog.LevelWarn))
+	assert.Equal(t, loglevel.Error, Level(slog.LevelError))
+	assert.Equal(t, loglevel.Error, Level(slog.LevelError+4))
+}
+
+func TestEnabled(t *testing.T) {
+	l, _ := newTestLogger(t, loglevel.Warn)
+	h := NewHandler(l)
+
+	assert.False(t, h.Enabled(context.Background(), slog.LevelInfo))
+	assert.True(t, h.Enabled(context.Background(), slog.LevelWarn))
+	assert.True(t, h.Enabled(context.Background(), slog.LevelError))
+}
+
+func TestHandle(t *testing.T) {
+	l, fn := newTestLogger(t, loglevel.All)
+
+	sl := slog.New(NewHandler(l)).With("service", "api").WithGroup("req")
+	sl.Info("handled 100% of request", "id", 7, slog.Group("user", "name", "jon doe"))
+	sl.Debug("debug message")
+
+	f, err := os.ReadFile(fn)
+	assert.Nil(t, err)
+
+	lines := strings.Split(string(f), "\n")
+	assert.Equal(t, 3, len(lines))
+
+	m := strings.Split(lines[0], " ")
+	assert.Equal(t, loglevel.Info.String(), m[2])
+	assert.Equal(t, "kslog.TestHandle", m[3])
+
+	//Messages are not used as format templates and group keys are qualified
+	assert.True(t, strings.HasSuffix(lines[0], `handled 100% of request service=api req.id=7 req.user.name="jon doe"`))
+
+	m = strings.Split(lines[1], " ")
+	assert.Equal(t, loglevel.Debug.String(), m[2])
+}
+
+func TestHandleRecordTime(t *testing.T) {
+	l, fn := newTestLogger(t, loglevel.All)
+	h := NewHandler(l)
+
+	//Records keep their own time
+	ts := time.Date(2024, 2, 15, 10, 37, 42, 0, time.Local)
+	assert.Nil(t, h.Handle(context.Background(), slog.NewRecord(ts, slog.LevelInfo, "replayed", 0)))
+
+	//A zero time is replaced with the current time
+	assert.Nil(t, h.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "no time", 0)))
+
+	f, err := os.ReadFile(fn)
+	assert.Nil(t, err)
+
+	lines := strings.Split(string(f), "\n")
+	assert.Equal(t, 3, len(lines))
+
+	assert.True(t, strings.HasPrefix(lines[0], "2024-02-15 10:37:42 INFO"))
+	assert.True(t, strings.HasPrefix(lines[1], time.Now().Format("2006-01-02")))
+}
+
+func TestHandleCaller(t *testing.T) {
+	o := klogger.DefaultOptions()
+	o.LogFileDir = t.TempDir()
+	o.LogLevel = loglevel.None
+	o.LogFileLevel = loglevel.All
+	o.LogTemplate = "{method} {caller} {file}:{line} g={goroutine} {msg}"
+
+	l := klogger.New(o)
+	defer l.Close()
+
+	slog.New(NewHandler(l)).Info("message")
+	_, _, line, _ := runtime.Caller(0)
+
+	f, err := os.ReadFile(o.LogFileDir + "/" + o.LogFileName)
+	assert.Nil(t, err)
+
+	//The file and line are those of the slog call
+	prefix := fmt.Sprintf("kslog.TestHandleCaller kslog_test.go:%d kslog_test.go:%d g=", line-1, line-1)
+	assert.True(t, strings.HasPrefix(string(f), prefix), string(f))
+	assert.False(t, strings.HasPrefix(string(f), prefix+"0 "))
+}
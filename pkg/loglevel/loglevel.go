@@ -1,7 +1,11 @@
 package loglevel
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 )
 
 // Type LogLevel is an int enum used to determine which logs should and should not be written by a configuration, as well as what value to log for each level
@@ -17,6 +21,7 @@ const (
 	None
 )
 
+const logLevelAll = "ALL"
 const logLevelTrace = "TRACE"
 const logLevelDebug = "DEBUG"
 const logLevelInfo = "INFO"
@@ -27,6 +32,8 @@ const logLevelNone = "NONE"
 // Function String is used when printing a LogLevel Object
 func (l LogLevel) String() string {
 	switch l {
+	case All:
+		return logLevelAll
 	case Trace:
 		return logLevelTrace
 	case Debug:
@@ -74,3 +81,87 @@ func GetLogLevelFromInterface(i interface{}) (LogLevel, error) {
 
 	return ll, nil
 }
+
+// Function Parse returns the LogLevel for a case-insensitive name such as "info", "WARNING" or "off", or for its integer value
+func Parse(s string) (LogLevel, error) {
+	s = strings.TrimSpace(s)
+
+	switch strings.ToUpper(s) {
+	case logLevelAll:
+		return All, nil
+	case logLevelTrace:
+		return Trace, nil
+	case logLevelDebug:
+		return Debug, nil
+	case logLevelInfo:
+		return Info, nil
+	case logLevelWarn, "WARNING":
+		return Warn, nil
+	case logLevelErr, "ERR":
+		return Error, nil
+	case logLevelNone, "OFF":
+		return None, nil
+	}
+
+	i, err := strconv.Atoi(s)
+
+	if err != nil || i < int(All) || i > int(None) {
+		return All, fmt.Errorf("%q is not a valid log level", s)
+	}
+
+	return LogLevel(i), nil
+}
+
+// Function MarshalText returns the name of the LogLevel
+func (l LogLevel) MarshalText() ([]byte, error) {
+	if l < All || l > None {
+		return nil, fmt.Errorf("%d is not a valid log level", int(l))
+	}
+
+	return []byte(l.String()), nil
+}
+
+// Function UnmarshalText sets the LogLevel from a name or integer value. See Parse
+func (l *LogLevel) UnmarshalText(b []byte) error {
+	ll, err := Parse(string(b))
+
+	if err != nil {
+		return err
+	}
+
+	*l = ll
+	return nil
+}
+
+// Function MarshalYAML returns the name of the LogLevel
+func (l LogLevel) MarshalYAML() (interface{}, error) {
+	b, err := l.MarshalText()
+	return string(b), err
+}
+
+// Function UnmarshalYAML sets the LogLevel from a yaml name or integer value
+func (l *LogLevel) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+
+	return l.UnmarshalText([]byte(s))
+}
+
+// Function UnmarshalJSON sets the LogLevel from a JSON string name or number
+func (l *LogLevel) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+
+	var s string
+
+	//Numbers are accepted as well as strings
+	if err := json.Unmarshal(b, &s); err != nil {
+		s = string(b)
+	}
+
+	return l.UnmarshalText([]byte(s))
+}
@@ -0,0 +1,93 @@
+package loglevel
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected LogLevel
+	}{
+		{"all", All},
+		{"trace", Trace},
+		{"DEBUG", Debug},
+		{" Info ", Info},
+		{"warn", Warn},
+		{"warning", Warn},
+		{"error", Error},
+		{"err", Error},
+		{"none", None},
+		{"OFF", None},
+		{"0", All},
+		{"3", Info},
+		{"6", None},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			l, err := Parse(tt.in)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, l)
+		})
+	}
+
+	for _, in := range []string{"", "verbose", "7", "-1", "1.5"} {
+		_, err := Parse(in)
+		assert.NotNil(t, err, in)
+	}
+}
+
+func TestText(t *testing.T) {
+	for l := All; l <= None; l++ {
+		b, err := l.MarshalText()
+		assert.Nil(t, err)
+
+		var u LogLevel
+		assert.Nil(t, u.UnmarshalText(b))
+		assert.Equal(t, l, u)
+	}
+
+	_, err := LogLevel(7).MarshalText()
+	assert.NotNil(t, err)
+}
+
+func TestJSON(t *testing.T) {
+	var v struct {
+		A LogLevel
+		B LogLevel
+		C LogLevel
+	}
+
+	err := json.Unmarshal([]byte(`{"A":"warning","B":2,"C":null}`), &v)
+	assert.Nil(t, err)
+	assert.Equal(t, Warn, v.A)
+	assert.Equal(t, Debug, v.B)
+	assert.Equal(t, All, v.C)
+
+	b, err := json.Marshal(v)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"A":"WARN","B":"DEBUG","C":"ALL"}`, string(b))
+
+	assert.NotNil(t, json.Unmarshal([]byte(`{"A":"loud"}`), &v))
+}
+
+func TestYAML(t *testing.T) {
+	var v struct {
+		A LogLevel
+		B LogLevel
+	}
+
+	err := yaml.Unmarshal([]byte("a: info\nb: 5\n"), &v)
+	assert.Nil(t, err)
+	assert.Equal(t, Info, v.A)
+	assert.Equal(t, Error, v.B)
+
+	b, err := yaml.Marshal(v)
+	assert.Nil(t, err)
+	assert.Equal(t, "a: INFO\nb: ERROR\n", string(b))
+}
@@ -0,0 +1,74 @@
+package klogger
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// Function Rotate writes any logs queued in async mode and then rolls the log file over immediately, regardless of its size and rollover interval
+func (lg *Logger) Rotate() {
+	if q := lg.asyncQueue(); q != nil {
+		q.Flush()
+	}
+
+	lg.out.file.file.Rotate()
+}
+
+// Function Reopen writes any logs queued in async mode and then closes and reopens the log file without renaming it. Use after the log file has been moved by an external tool such as logrotate
+func (lg *Logger) Reopen() {
+	if q := lg.asyncQueue(); q != nil {
+		q.Flush()
+	}
+
+	lg.out.file.file.Reopen()
+}
+
+// Function ReopenOnSignal starts a goroutine calling Reopen each time one of the given signals is received. Returns a function which stops it
+// sigs - the signals to reopen on. If none are given SIGHUP is used
+func (lg *Logger) ReopenOnSignal(sigs ...os.Signal) func() {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGHUP}
+	}
+
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+
+	signal.Notify(ch, sigs...)
+
+	go func() {
+		for {
+			select {
+			case <-ch:
+				lg.Reopen()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
+
+// Function Rotate rolls the log file of the default Logger over immediately
+func Rotate() {
+	std.Rotate()
+}
+
+// Function Reopen closes and reopens the log file of the default Logger without renaming it
+func Reopen() {
+	std.Reopen()
+}
+
+// Function ReopenOnSignal reopens the log file of the default Logger each time one of the given signals, or SIGHUP if none are given, is received. Returns a function which stops it
+func ReopenOnSignal(sigs ...os.Signal) func() {
+	return std.ReopenOnSignal(sigs...)
+}
@@ -0,0 +1,170 @@
+package klogger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jon-kamis/klogger/internal/config"
+	"github.com/jon-kamis/klogger/internal/constants"
+	"github.com/jon-kamis/klogger/internal/filelogger"
+	"github.com/jon-kamis/klogger/pkg/loglevel"
+)
+
+// Type Sink is an output destination for logs. Sinks must be safe for concurrent use
+type Sink interface {
+	Write(r Record) error //Formats and writes a single log
+	Flush() error         //Writes any buffered logs
+	Close() error         //Releases any resources held by the Sink
+}
+
+// Type sinkEntry is a Sink registered with a Logger along with the lowest log level written to it
+type sinkEntry struct {
+	sink  Sink
+	level loglevel.LogLevel
+}
+
+// Type writerSink is a Sink writing formatted logs to an io.Writer
+type writerSink struct {
+	mu     sync.Mutex
+	w      io.Writer
+	format Formatter
+}
+
+// Function NewWriterSink returns a Sink writing logs to w, one per line. The writer is not closed by the Sink
+// f - the Formatter to use. If nil logs are written as text
+func NewWriterSink(w io.Writer, f Formatter) Sink {
+	if f == nil {
+		f = FormatText
+	}
+
+	return &writerSink{w: w, format: f}
+}
+
+// Function NewStdoutSink returns a Sink writing logs to stdout
+// f - the Formatter to use. If nil logs are written as text
+func NewStdoutSink(f Formatter) Sink {
+	return NewWriterSink(os.Stdout, f)
+}
+
+// Function NewStderrSink returns a Sink writing logs to stderr
+// f - the Formatter to use. If nil logs are written as text
+func NewStderrSink(f Formatter) Sink {
+	return NewWriterSink(os.Stderr, f)
+}
+
+// Function Write formats and writes a log. The lines of a log are written together so that they are not interleaved with other logs
+func (s *writerSink) Write(r Record) error {
+	b := []byte(s.format(r) + "\n")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.w.Write(b)
+	return err
+}
+
+// Function Flush flushes the writer if it buffers its output
+func (s *writerSink) Flush() error {
+	f, ok := s.w.(interface{ Flush() error })
+
+	if !ok {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return f.Flush()
+}
+
+// Function Close flushes the writer. The writer itself is left open
+func (s *writerSink) Close() error {
+	return s.Flush()
+}
+
+// Type fileSink is a Sink writing logs to a rolling log file
+type fileSink struct {
+	file     *filelogger.FileLogger
+	conf     func() config.KloggerConfig //Provides the FileErrorPolicy property
+	fallback io.Writer                   //Written to by the stderr FileErrorPolicy
+	format   Formatter
+}
+
+// Function NewFileSink returns a Sink writing logs to the log file and rolling it over as described by the file and rollover settings of o. Errors from rolling the file over are passed to o.ErrorHandler
+// f - the Formatter to use. If nil logs are written in the LogFileFormat using the LogTemplate and timestamp settings of o
+func NewFileSink(o Options, f Formatter) Sink {
+	c := o.toConfig()
+	conf := func() config.KloggerConfig { return c }
+
+	if f == nil {
+		f = formatterFor(c.LogFileFormat, c)
+	}
+
+	fl := filelogger.New(conf)
+
+	if o.ErrorHandler != nil {
+		fl.SetErrorHandler(o.ErrorHandler)
+	}
+
+	return &fileSink{
+		file:     fl,
+		conf:     conf,
+		fallback: os.Stderr,
+		format:   f,
+	}
+}
+
+// Function Write formats and writes a log to the log file. If it cannot be written the FileErrorPolicy decides what happens to the log, and the error is returned unless a retry wrote the log. If it is written but cannot be synced the error is returned without applying the policy
+func (s *fileSink) Write(r Record) error {
+	msg := s.format(r)
+
+	err := s.file.WriteLogToFile(msg, r.Level)
+
+	//A log which was written but not synced is not written again, and the error is only reported
+	if err == nil || errors.Is(err, filelogger.ErrSync) {
+		return err
+	}
+
+	switch s.conf().FileErrorPolicy {
+	case constants.FileErrorPolicyDrop:
+		return fmt.Errorf("%w, log dropped", err)
+
+	case constants.FileErrorPolicyRetry:
+		backoff := constants.FileErrorRetryBackoff * time.Millisecond
+
+		for i := 0; i < constants.FileErrorRetries; i++ {
+			time.Sleep(backoff)
+			backoff *= 2
+
+			//A log written by a retry is not an error
+			if rerr := s.file.WriteLogToFile(msg, r.Level); rerr == nil {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("%w, log dropped after %d retries", err, constants.FileErrorRetries)
+
+	default:
+		if _, ferr := io.WriteString(s.fallback, msg+"\n"); ferr != nil {
+			return fmt.Errorf("%w, log dropped as it could not be written to stderr: %v", err, ferr)
+		}
+
+		return fmt.Errorf("%w, log written to stderr", err)
+	}
+}
+
+// Function Flush syncs the log file to disk
+func (s *fileSink) Flush() error {
+	return s.file.Sync()
+}
+
+// Function Close closes the log file and waits for rolled over files to finish compressing. The log file is reopened if another log is written
+func (s *fileSink) Close() error {
+	s.file.CloseFile()
+	s.file.WaitCompression()
+	return nil
+}
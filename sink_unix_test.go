@@ -0,0 +1,69 @@
+//go:build unix
+
+package klogger
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/jon-kamis/klogger/internal/constants"
+	"github.com/jon-kamis/klogger/internal/filelogger"
+	"github.com/jon-kamis/klogger/pkg/loglevel"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileSyncError(t *testing.T) {
+	method := "TestFileSyncError"
+
+	for _, policy := range []string{constants.FileErrorPolicyStderr, constants.FileErrorPolicyRetry, constants.FileErrorPolicyDrop} {
+		t.Run(policy, func(t *testing.T) {
+			o := DefaultOptions()
+			o.LogFileDir = t.TempDir()
+			o.LogLevel = loglevel.None
+			o.LogFileLevel = loglevel.All
+			o.FileErrorPolicy = policy
+			o.FileCheckInterval = 0
+
+			//Writes to a named pipe succeed but syncing it fails
+			fn := o.LogFileDir + "/" + o.LogFileName
+			assert.Nil(t, syscall.Mkfifo(fn, 0644))
+
+			//Opening the pipe for reading and writing does not wait for a writer
+			r, err := os.OpenFile(fn, os.O_RDWR, 0)
+			assert.Nil(t, err)
+			defer r.Close()
+
+			var errs []error
+			o.ErrorHandler = func(err error) { errs = append(errs, err) }
+
+			l := New(o)
+			defer l.Close()
+
+			var fallback bytes.Buffer
+			l.out.file.fallback = &fallback
+
+			l.Info(method, "once")
+			l.Info(method, "twice")
+
+			//Each log is written once and the sync errors are only reported
+			sc := bufio.NewScanner(r)
+
+			for _, want := range []string{"once", "twice"} {
+				assert.True(t, sc.Scan())
+				assert.True(t, strings.HasSuffix(sc.Text(), method+" "+want))
+			}
+
+			assert.Equal(t, "", fallback.String())
+			assert.Equal(t, 2, len(errs))
+
+			for _, err := range errs {
+				assert.True(t, errors.Is(err, filelogger.ErrSync), err)
+			}
+		})
+	}
+}